@@ -0,0 +1,142 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agents
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/build"
+	"github.com/cloudfoundry/libcfbuildpack/v2/helper"
+	"github.com/cloudfoundry/libcfbuildpack/v2/layers"
+)
+
+// Dependency is the name of the layer containing the service name defaults of the enabled Java agents.
+const Dependency = "java-agents"
+
+// ServiceNames are the launch environment variables that configure the service name reported by known Java agents,
+// keyed by dependency id.
+var ServiceNames = map[string]string{
+	"elastic-apm-agent":       "ELASTIC_APM_SERVICE_NAME",
+	"opentelemetry-javaagent": "OTEL_SERVICE_NAME",
+}
+
+// Agents represents the Java agents attached to an application at launch.
+type Agents struct {
+	agents      []layers.DependencyLayer
+	layer       layers.Layer
+	serviceName string
+}
+
+// Contribute makes the contribution to launch.
+func (a Agents) Contribute() error {
+	for _, l := range a.agents {
+		if err := l.Contribute(func(artifact string, layer layers.DependencyLayer) error {
+			destination := filepath.Join(layer.Root, filepath.Base(artifact))
+
+			layer.Logger.Body("Copying to %s", layer.Root)
+			if err := helper.CopyFile(artifact, destination); err != nil {
+				return err
+			}
+
+			return layer.AppendLaunchEnv("JAVA_OPTS", " -javaagent:%s", destination)
+		}, layers.Launch); err != nil {
+			return err
+		}
+	}
+
+	n := a.serviceNames()
+	if len(n) == 0 {
+		return nil
+	}
+
+	return a.layer.Contribute(n, func(layer layers.Layer) error {
+		if err := os.RemoveAll(layer.Root); err != nil {
+			return err
+		}
+
+		var variables []string
+		for v := range n {
+			variables = append(variables, v)
+		}
+		sort.Strings(variables)
+
+		for _, v := range variables {
+			if err := layer.DefaultLaunchEnv(v, "%s", n[v]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, layers.Launch)
+}
+
+func (a Agents) serviceNames() serviceNames {
+	n := serviceNames{}
+
+	if a.serviceName == "" {
+		return n
+	}
+
+	for _, l := range a.agents {
+		if v, ok := ServiceNames[l.Dependency.ID]; ok {
+			n[v] = a.serviceName
+		}
+	}
+
+	return n
+}
+
+type serviceNames map[string]string
+
+func (s serviceNames) Identity() (string, string) {
+	return "Java Agent Service Names", ""
+}
+
+// NewAgents creates a new Agents instance from the comma separated dependency ids in $BP_JAVA_AGENTS.  The service
+// name, typically the application's artifact ID, is contributed as the default service name of known agents.
+func NewAgents(build build.Build, serviceName string) (Agents, error) {
+	a := Agents{layer: build.Layers.Layer(Dependency), serviceName: serviceName}
+
+	s, ok := os.LookupEnv("BP_JAVA_AGENTS")
+	if !ok {
+		return a, nil
+	}
+
+	deps, err := build.Buildpack.Dependencies()
+	if err != nil {
+		return Agents{}, err
+	}
+
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id == "" {
+			continue
+		}
+
+		dep, err := deps.Best(id, "", build.Stack)
+		if err != nil {
+			return Agents{}, fmt.Errorf("unable to find Java agent %s: %w", id, err)
+		}
+
+		a.agents = append(a.agents, build.Layers.DependencyLayer(dep))
+	}
+
+	return a, nil
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agents_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	bpbuildpack "github.com/buildpacks/libbuildpack/v2/buildpack"
+	bplogger "github.com/buildpacks/libbuildpack/v2/logger"
+	"github.com/buildpacks/libbuildpack/v2/stack"
+	"github.com/cloudfoundry/libcfbuildpack/v2/buildpack"
+	"github.com/cloudfoundry/libcfbuildpack/v2/logger"
+	"github.com/cloudfoundry/libcfbuildpack/v2/test"
+	"github.com/cloudfoundry/spring-boot-cnb/agents"
+	"github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestAgents(t *testing.T) {
+	spec.Run(t, "Agents", func(t *testing.T, when spec.G, it spec.S) {
+
+		g := gomega.NewWithT(t)
+
+		var f *test.BuildFactory
+
+		it.Before(func() {
+			f = test.NewBuildFactory(t)
+		})
+
+		it("contributes nothing by default", func() {
+			a, err := agents.NewAgents(f.Build, "test-artifact")
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			g.Expect(a.Contribute()).To(gomega.Succeed())
+
+			g.Expect(f.Build.Layers.Layer(agents.Dependency).Root).NotTo(gomega.BeADirectory())
+		})
+
+		it("contributes agents", func() {
+			f.AddDependency("opentelemetry-javaagent", filepath.Join("testdata", "stub-java-agent.jar"))
+			f.AddDependency("test-agent", filepath.Join("testdata", "stub-java-agent.jar"))
+			defer test.ReplaceEnv(t, "BP_JAVA_AGENTS", "opentelemetry-javaagent, test-agent")()
+
+			a, err := agents.NewAgents(f.Build, "test-artifact")
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			g.Expect(a.Contribute()).To(gomega.Succeed())
+
+			for _, id := range []string{"opentelemetry-javaagent", "test-agent"} {
+				layer := f.Build.Layers.Layer(id)
+				g.Expect(layer).To(test.HaveLayerMetadata(false, false, true))
+				g.Expect(filepath.Join(layer.Root, "stub-java-agent.jar")).To(gomega.BeARegularFile())
+				g.Expect(layer).To(test.HaveAppendLaunchEnvironment("JAVA_OPTS", " -javaagent:%s",
+					filepath.Join(layer.Root, "stub-java-agent.jar")))
+			}
+
+			layer := f.Build.Layers.Layer(agents.Dependency)
+			g.Expect(layer).To(test.HaveLayerMetadata(false, false, true))
+			g.Expect(layer).To(test.HaveDefaultLaunchEnvironment("OTEL_SERVICE_NAME", "test-artifact"))
+		})
+
+		it("contributes service names containing format verbs", func() {
+			f.AddDependency("opentelemetry-javaagent", filepath.Join("testdata", "stub-java-agent.jar"))
+			defer test.ReplaceEnv(t, "BP_JAVA_AGENTS", "opentelemetry-javaagent")()
+
+			a, err := agents.NewAgents(f.Build, "test-%d-artifact")
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			g.Expect(a.Contribute()).To(gomega.Succeed())
+
+			layer := f.Build.Layers.Layer(agents.Dependency)
+			g.Expect(layer).To(test.HaveDefaultLaunchEnvironment("OTEL_SERVICE_NAME", "%s", "test-%d-artifact"))
+		})
+
+		it("declares dependencies for agents with service names", func() {
+			b, err := bpbuildpack.New("..", bplogger.Logger{})
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			deps, err := buildpack.NewBuildpack(b, logger.Logger{}).Dependencies()
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			for id := range agents.ServiceNames {
+				for _, s := range []stack.Stack{"io.buildpacks.stacks.bionic", "org.cloudfoundry.stacks.cflinuxfs3"} {
+					_, err := deps.Best(id, "", s)
+					g.Expect(err).NotTo(gomega.HaveOccurred())
+				}
+			}
+		})
+
+		it("rejects unknown agents", func() {
+			defer test.ReplaceEnv(t, "BP_JAVA_AGENTS", "test-agent")()
+
+			_, err := agents.NewAgents(f.Build, "test-artifact")
+			g.Expect(err).To(gomega.MatchError(gomega.HavePrefix("unable to find Java agent test-agent")))
+		})
+	}, spec.Report(report.Terminal{}))
+}
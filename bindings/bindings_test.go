@@ -0,0 +1,53 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/test"
+	"github.com/cloudfoundry/spring-boot-cnb/bindings"
+	"github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestBindings(t *testing.T) {
+	spec.Run(t, "Bindings", func(t *testing.T, _ spec.G, it spec.S) {
+
+		g := gomega.NewWithT(t)
+
+		var f *test.BuildFactory
+
+		it.Before(func() {
+			f = test.NewBuildFactory(t)
+		})
+
+		it("contributes helper", func() {
+			test.TouchFile(t, f.Build.Buildpack.Root, "bin", bindings.Dependency)
+
+			g.Expect(bindings.NewBindings(f.Build).Contribute()).To(gomega.Succeed())
+
+			layer := f.Build.Layers.Layer(bindings.Dependency)
+			g.Expect(layer).To(test.HaveLayerMetadata(false, false, true))
+			g.Expect(filepath.Join(layer.Root, "bin", bindings.Dependency)).To(gomega.BeARegularFile())
+			g.Expect(layer).To(test.HaveProfile(bindings.Dependency, `eval "$(%s)"`,
+				filepath.Join(layer.Root, "bin", bindings.Dependency)))
+		})
+	}, spec.Report(report.Terminal{}))
+}
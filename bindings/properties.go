@@ -0,0 +1,241 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cloudfoundry/spring-boot-cnb/environment"
+)
+
+// binding is a service binding following the Kubernetes service binding specification.
+type binding struct {
+	name     string
+	provider string
+	secret   map[string]string
+}
+
+func (b binding) copy(properties environment.Properties, mappings map[string]string) {
+	for k, p := range mappings {
+		if v, ok := b.secret[k]; ok {
+			properties[p] = v
+		}
+	}
+}
+
+var mappers = map[string]func(binding binding, properties environment.Properties){
+	"kafka":      kafka,
+	"mongodb":    mongodb,
+	"mysql":      jdbc("mysql"),
+	"oauth2":     oauth2,
+	"postgresql": jdbc("postgresql"),
+	"rabbitmq":   rabbitmq,
+	"redis":      redis,
+}
+
+// NewProperties creates a new Properties from the service bindings in a $SERVICE_BINDING_ROOT directory.  Bindings
+// are only mapped when exactly one binding of a given type exists, except for oauth2 bindings which are each mapped to
+// a client registration named after the binding.
+func NewProperties(root string) (environment.Properties, error) {
+	p := environment.Properties{}
+
+	if root == "" {
+		return p, nil
+	}
+
+	bindings, err := read(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var types []string
+	for t := range bindings {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	for _, t := range types {
+		m, ok := mappers[t]
+		if !ok {
+			continue
+		}
+
+		if b := bindings[t]; t == "oauth2" || len(b) == 1 {
+			for _, b := range b {
+				m(b, p)
+			}
+		}
+	}
+
+	return p, nil
+}
+
+func read(root string) (map[string][]binding, error) {
+	bindings := make(map[string][]binding)
+
+	entries, err := ioutil.ReadDir(root)
+	if os.IsNotExist(err) {
+		return bindings, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+
+		secret, err := readSecret(filepath.Join(root, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		t, ok := secret["type"]
+		if !ok {
+			continue
+		}
+
+		b := binding{name: e.Name(), provider: secret["provider"], secret: secret}
+		bindings[strings.ToLower(t)] = append(bindings[strings.ToLower(t)], b)
+	}
+
+	return bindings, nil
+}
+
+func readSecret(path string) (map[string]string, error) {
+	secret := make(map[string]string)
+
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+
+		f := filepath.Join(path, e.Name())
+
+		// Kubernetes mounts secret entries as symlinks so resolve them before checking for regular files
+		if i, err := os.Stat(f); err != nil {
+			return nil, err
+		} else if !i.Mode().IsRegular() {
+			continue
+		}
+
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read binding %s: %w", filepath.Base(path), err)
+		}
+
+		secret[e.Name()] = strings.TrimSpace(string(b))
+	}
+
+	return secret, nil
+}
+
+func jdbc(scheme string) func(binding binding, properties environment.Properties) {
+	return func(binding binding, properties environment.Properties) {
+		if v, ok := binding.secret["jdbc-url"]; ok {
+			properties["spring.datasource.url"] = v
+		} else if h, ok := binding.secret["host"]; ok {
+			u := fmt.Sprintf("jdbc:%s://%s", scheme, h)
+
+			if p, ok := binding.secret["port"]; ok {
+				u = fmt.Sprintf("%s:%s", u, p)
+			}
+
+			if d, ok := binding.secret["database"]; ok {
+				u = fmt.Sprintf("%s/%s", u, d)
+			}
+
+			properties["spring.datasource.url"] = u
+		}
+
+		binding.copy(properties, map[string]string{
+			"password": "spring.datasource.password",
+			"username": "spring.datasource.username",
+		})
+	}
+}
+
+func kafka(binding binding, properties environment.Properties) {
+	binding.copy(properties, map[string]string{
+		"bootstrap-servers": "spring.kafka.bootstrap-servers",
+	})
+}
+
+func mongodb(binding binding, properties environment.Properties) {
+	binding.copy(properties, map[string]string{
+		"authentication-database": "spring.data.mongodb.authentication-database",
+		"database":                "spring.data.mongodb.database",
+		"host":                    "spring.data.mongodb.host",
+		"password":                "spring.data.mongodb.password",
+		"port":                    "spring.data.mongodb.port",
+		"uri":                     "spring.data.mongodb.uri",
+		"username":                "spring.data.mongodb.username",
+	})
+}
+
+func oauth2(binding binding, properties environment.Properties) {
+	r := fmt.Sprintf("spring.security.oauth2.client.registration[%s]", binding.name)
+
+	binding.copy(properties, map[string]string{
+		"authorization-grant-type":     fmt.Sprintf("%s.authorization-grant-type", r),
+		"client-authentication-method": fmt.Sprintf("%s.client-authentication-method", r),
+		"client-id":                    fmt.Sprintf("%s.client-id", r),
+		"client-secret":                fmt.Sprintf("%s.client-secret", r),
+		"redirect-uri":                 fmt.Sprintf("%s.redirect-uri", r),
+		"scope":                        fmt.Sprintf("%s.scope", r),
+	})
+
+	if binding.provider != "" {
+		properties[fmt.Sprintf("%s.provider", r)] = binding.provider
+
+		binding.copy(properties, map[string]string{
+			"issuer-uri": fmt.Sprintf("spring.security.oauth2.client.provider[%s].issuer-uri", binding.provider),
+		})
+	}
+}
+
+func rabbitmq(binding binding, properties environment.Properties) {
+	binding.copy(properties, map[string]string{
+		"addresses":    "spring.rabbitmq.addresses",
+		"host":         "spring.rabbitmq.host",
+		"password":     "spring.rabbitmq.password",
+		"port":         "spring.rabbitmq.port",
+		"username":     "spring.rabbitmq.username",
+		"virtual-host": "spring.rabbitmq.virtual-host",
+	})
+}
+
+func redis(binding binding, properties environment.Properties) {
+	binding.copy(properties, map[string]string{
+		"database": "spring.redis.database",
+		"host":     "spring.redis.host",
+		"password": "spring.redis.password",
+		"port":     "spring.redis.port",
+		"ssl":      "spring.redis.ssl",
+		"url":      "spring.redis.url",
+	})
+}
@@ -0,0 +1,174 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindings_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/test"
+	"github.com/cloudfoundry/spring-boot-cnb/bindings"
+	"github.com/cloudfoundry/spring-boot-cnb/environment"
+	"github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestProperties(t *testing.T) {
+	spec.Run(t, "Properties", func(t *testing.T, _ spec.G, it spec.S) {
+
+		g := gomega.NewWithT(t)
+
+		var root string
+
+		it.Before(func() {
+			root = test.ScratchDir(t, "bindings")
+		})
+
+		binding := func(name string, secret map[string]string) {
+			for k, v := range secret {
+				test.WriteFile(t, filepath.Join(root, name, k), v)
+			}
+		}
+
+		it("returns empty properties without SERVICE_BINDING_ROOT", func() {
+			g.Expect(bindings.NewProperties("")).To(gomega.BeEmpty())
+		})
+
+		it("returns empty properties when SERVICE_BINDING_ROOT does not exist", func() {
+			g.Expect(bindings.NewProperties(filepath.Join(root, "does-not-exist"))).To(gomega.BeEmpty())
+		})
+
+		it("ignores bindings without type", func() {
+			binding("test-binding", map[string]string{"host": "test-host"})
+
+			g.Expect(bindings.NewProperties(root)).To(gomega.BeEmpty())
+		})
+
+		it("maps postgresql", func() {
+			binding("test-db", map[string]string{
+				"type":     "postgresql\n",
+				"host":     "test-host",
+				"port":     "5432",
+				"database": "test-database",
+				"username": "test-username",
+				"password": "test-password",
+			})
+
+			g.Expect(bindings.NewProperties(root)).To(gomega.Equal(environment.Properties{
+				"spring.datasource.url":      "jdbc:postgresql://test-host:5432/test-database",
+				"spring.datasource.username": "test-username",
+				"spring.datasource.password": "test-password",
+			}))
+		})
+
+		it("maps mysql with jdbc-url", func() {
+			binding("test-db", map[string]string{
+				"type":     "mysql",
+				"jdbc-url": "jdbc:mysql://test-host:3306/test-database",
+				"username": "test-username",
+			})
+
+			g.Expect(bindings.NewProperties(root)).To(gomega.Equal(environment.Properties{
+				"spring.datasource.url":      "jdbc:mysql://test-host:3306/test-database",
+				"spring.datasource.username": "test-username",
+			}))
+		})
+
+		it("maps rabbitmq", func() {
+			binding("test-rabbit", map[string]string{
+				"type":         "rabbitmq",
+				"host":         "test-host",
+				"port":         "5672",
+				"username":     "test-username",
+				"password":     "test-password",
+				"virtual-host": "test-vhost",
+			})
+
+			g.Expect(bindings.NewProperties(root)).To(gomega.Equal(environment.Properties{
+				"spring.rabbitmq.host":         "test-host",
+				"spring.rabbitmq.port":         "5672",
+				"spring.rabbitmq.username":     "test-username",
+				"spring.rabbitmq.password":     "test-password",
+				"spring.rabbitmq.virtual-host": "test-vhost",
+			}))
+		})
+
+		it("maps redis", func() {
+			binding("test-redis", map[string]string{"type": "redis", "host": "test-host", "password": "test-password"})
+
+			g.Expect(bindings.NewProperties(root)).To(gomega.Equal(environment.Properties{
+				"spring.redis.host":     "test-host",
+				"spring.redis.password": "test-password",
+			}))
+		})
+
+		it("maps mongodb", func() {
+			binding("test-mongo", map[string]string{"type": "mongodb", "uri": "mongodb://test-host:27017/test-database"})
+
+			g.Expect(bindings.NewProperties(root)).To(gomega.Equal(environment.Properties{
+				"spring.data.mongodb.uri": "mongodb://test-host:27017/test-database",
+			}))
+		})
+
+		it("maps kafka", func() {
+			binding("test-kafka", map[string]string{"type": "kafka", "bootstrap-servers": "test-host-1:9092,test-host-2:9092"})
+
+			g.Expect(bindings.NewProperties(root)).To(gomega.Equal(environment.Properties{
+				"spring.kafka.bootstrap-servers": "test-host-1:9092,test-host-2:9092",
+			}))
+		})
+
+		it("maps each oauth2 binding to a registration", func() {
+			binding("alpha", map[string]string{
+				"type":          "oauth2",
+				"provider":      "test-provider",
+				"client-id":     "test-client-id",
+				"client-secret": "test-client-secret",
+				"issuer-uri":    "https://test-issuer",
+			})
+			binding("My-Client", map[string]string{"type": "oauth2", "client-id": "test-client-id"})
+
+			g.Expect(bindings.NewProperties(root)).To(gomega.Equal(environment.Properties{
+				"spring.security.oauth2.client.registration[alpha].client-id":      "test-client-id",
+				"spring.security.oauth2.client.registration[alpha].client-secret":  "test-client-secret",
+				"spring.security.oauth2.client.registration[alpha].provider":       "test-provider",
+				"spring.security.oauth2.client.provider[test-provider].issuer-uri": "https://test-issuer",
+				"spring.security.oauth2.client.registration[My-Client].client-id":  "test-client-id",
+			}))
+		})
+
+		it("does not map ambiguous bindings", func() {
+			binding("test-redis-1", map[string]string{"type": "redis", "host": "test-host-1"})
+			binding("test-redis-2", map[string]string{"type": "redis", "host": "test-host-2"})
+
+			g.Expect(bindings.NewProperties(root)).To(gomega.BeEmpty())
+		})
+
+		it("resolves symlinked secret entries", func() {
+			test.WriteFile(t, filepath.Join(root, "test-redis", "..data", "type"), "redis")
+			test.WriteFile(t, filepath.Join(root, "test-redis", "..data", "host"), "test-host")
+			g.Expect(os.Symlink(filepath.Join("..data", "type"), filepath.Join(root, "test-redis", "type"))).To(gomega.Succeed())
+			g.Expect(os.Symlink(filepath.Join("..data", "host"), filepath.Join(root, "test-redis", "host"))).To(gomega.Succeed())
+
+			g.Expect(bindings.NewProperties(root)).To(gomega.Equal(environment.Properties{
+				"spring.redis.host": "test-host",
+			}))
+		})
+	}, spec.Report(report.Terminal{}))
+}
@@ -22,7 +22,10 @@ import (
 
 	"github.com/cloudfoundry/libcfbuildpack/v2/build"
 	"github.com/cloudfoundry/libcfbuildpack/v2/buildpackplan"
+	"github.com/cloudfoundry/spring-boot-cnb/agents"
+	"github.com/cloudfoundry/spring-boot-cnb/bindings"
 	"github.com/cloudfoundry/spring-boot-cnb/cli"
+	"github.com/cloudfoundry/spring-boot-cnb/cloudfoundry"
 	"github.com/cloudfoundry/spring-boot-cnb/springboot"
 )
 
@@ -42,7 +45,11 @@ func main() {
 }
 
 func b(build build.Build) (int, error) {
-	var ps []buildpackplan.Plan
+	var (
+		launch      bool
+		ps          []buildpackplan.Plan
+		serviceName string
+	)
 
 	if s, ok, err := springboot.NewSpringBoot(build); err != nil {
 		return build.Failure(102), err
@@ -59,6 +66,8 @@ func b(build build.Build) (int, error) {
 		}
 
 		ps = append(ps, p)
+		launch = true
+		serviceName = s.Metadata.ArtifactID
 	}
 
 	if c, ok, err := cli.NewCommand(build); err != nil {
@@ -77,6 +86,24 @@ func b(build build.Build) (int, error) {
 		if err = c.Contribute(); err != nil {
 			return build.Failure(103), err
 		}
+
+		launch = true
+	}
+
+	if launch {
+		if a, err := agents.NewAgents(build, serviceName); err != nil {
+			return build.Failure(102), err
+		} else if err := a.Contribute(); err != nil {
+			return build.Failure(103), err
+		}
+
+		if err := cloudfoundry.NewCloudFoundry(build).Contribute(); err != nil {
+			return build.Failure(103), err
+		}
+
+		if err := bindings.NewBindings(build).Contribute(); err != nil {
+			return build.Failure(103), err
+		}
 	}
 
 	return build.Success(ps...)
@@ -27,6 +27,7 @@ import (
 	"github.com/cloudfoundry/libcfbuildpack/v2/build"
 	"github.com/cloudfoundry/libcfbuildpack/v2/helper"
 	"github.com/cloudfoundry/libcfbuildpack/v2/layers"
+	"github.com/cloudfoundry/spring-boot-cnb/process"
 )
 
 var (
@@ -40,6 +41,7 @@ type Command struct {
 	groovyFiles groovyFiles
 	layer       layers.Layer
 	layers      layers.Layers
+	processes   process.Configuration
 }
 
 // Contribute makes the contribution to launch.
@@ -52,13 +54,12 @@ func (c Command) Contribute() error {
 
 	command := "spring run -cp $CLASSPATH $GROOVY_FILES"
 
-	return c.layers.WriteApplicationMetadata(layers.Metadata{
-		Processes: layers.Processes{
-			{Type: "spring-boot-cli", Command: command},
-			{Type: "task", Command: command},
-			{Type: "web", Command: command},
-		},
-	})
+	processes, err := c.processes.ProcessTypes(Dependency, command)
+	if err != nil {
+		return err
+	}
+
+	return c.layers.WriteApplicationMetadata(layers.Metadata{Processes: processes})
 }
 
 type groovyFiles []string
@@ -91,10 +92,16 @@ func NewCommand(build build.Build) (Command, bool, error) {
 		return Command{}, false, nil
 	}
 
+	p, err := process.NewConfiguration(build.Application)
+	if err != nil {
+		return Command{}, false, err
+	}
+
 	return Command{
 		groovyFiles(candidates),
 		build.Layers.Layer("command"),
 		build.Layers,
+		p,
 	}, true, nil
 }
 
@@ -0,0 +1,52 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cloudfoundry
+
+import (
+	"path/filepath"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/build"
+	"github.com/cloudfoundry/libcfbuildpack/v2/helper"
+	"github.com/cloudfoundry/libcfbuildpack/v2/layers"
+)
+
+// Dependency is the id of the helper that maps the Cloud Foundry launch environment to Spring Boot properties.
+const Dependency = "cloud-foundry-properties"
+
+// CloudFoundry represents the helper that maps the Cloud Foundry launch environment to Spring Boot properties.
+type CloudFoundry struct {
+	layer layers.HelperLayer
+}
+
+// Contribute makes the contribution to launch.
+func (c CloudFoundry) Contribute() error {
+	return c.layer.Contribute(func(artifact string, layer layers.HelperLayer) error {
+		b := filepath.Join(layer.Root, "bin", Dependency)
+
+		layer.Logger.Body("Copying to %s", b)
+		if err := helper.CopyFile(artifact, b); err != nil {
+			return err
+		}
+
+		return layer.WriteProfile(Dependency, `eval "$(%s)"`, b)
+	}, layers.Launch)
+}
+
+// NewCloudFoundry creates a new CloudFoundry instance.
+func NewCloudFoundry(build build.Build) CloudFoundry {
+	return CloudFoundry{build.Layers.HelperLayer(Dependency, "Cloud Foundry Properties")}
+}
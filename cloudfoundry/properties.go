@@ -0,0 +1,283 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cloudfoundry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/cloudfoundry/spring-boot-cnb/environment"
+)
+
+type service struct {
+	Credentials map[string]interface{} `json:"credentials"`
+	Label       string                 `json:"label"`
+	Name        string                 `json:"name"`
+	Tags        []string               `json:"tags"`
+}
+
+func (s service) matches(candidates ...string) bool {
+	for _, c := range candidates {
+		if strings.Contains(strings.ToLower(s.Label), c) {
+			return true
+		}
+
+		for _, t := range s.Tags {
+			if strings.Contains(strings.ToLower(t), c) {
+				return true
+			}
+		}
+
+		if u, ok := s.uri(); ok && strings.HasPrefix(u.Scheme, c) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s service) string(keys ...string) (string, bool) {
+	for _, k := range keys {
+		switch v := s.Credentials[k].(type) {
+		case string:
+			if v != "" {
+				return v, true
+			}
+		case float64:
+			return fmt.Sprintf("%.0f", v), true
+		}
+	}
+
+	return "", false
+}
+
+func (s service) uri() (*url.URL, bool) {
+	v, ok := s.string("uri", "url")
+	if !ok {
+		return nil, false
+	}
+
+	u, err := url.Parse(v)
+	if err != nil || u.Scheme == "" {
+		return nil, false
+	}
+
+	return u, true
+}
+
+// mapper maps the credentials of a single service to Spring Boot properties.
+type mapper struct {
+	name       string
+	candidates []string
+	mapping    func(service service, properties environment.Properties)
+}
+
+var mappers = []mapper{
+	{"database", []string{"mysql", "mariadb", "postgres", "sqlserver", "oracle", "db2"}, database},
+	{"rabbitmq", []string{"rabbit", "amqp"}, rabbitmq},
+	{"redis", []string{"redis"}, redis},
+	{"mongodb", []string{"mongo"}, mongodb},
+}
+
+// NewProperties creates a new Properties from the values of the $PORT and $VCAP_SERVICES environment variables.
+// Service credentials are only mapped when exactly one service of a given kind is bound.
+func NewProperties(port string, vcapServices string) (environment.Properties, error) {
+	p := environment.Properties{}
+
+	if port != "" {
+		p["server.port"] = port
+	}
+
+	if vcapServices == "" {
+		return p, nil
+	}
+
+	var in map[string][]service
+	if err := json.Unmarshal([]byte(vcapServices), &in); err != nil {
+		return nil, fmt.Errorf("unable to parse VCAP_SERVICES: %w", err)
+	}
+
+	var labels []string
+	for l := range in {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+
+	matches := make(map[string][]service)
+	for _, l := range labels {
+		for _, s := range in[l] {
+			for _, m := range mappers {
+				if s.matches(m.candidates...) {
+					matches[m.name] = append(matches[m.name], s)
+					break
+				}
+			}
+		}
+	}
+
+	for _, m := range mappers {
+		if s := matches[m.name]; len(s) == 1 {
+			m.mapping(s[0], p)
+		}
+	}
+
+	return p, nil
+}
+
+// jdbcURLs map the URI of a database service to a JDBC URL for each kind of database.
+var jdbcURLs = []struct {
+	candidate string
+	url       func(u *url.URL) string
+}{
+	{"mysql", standardJDBCURL("mysql")},
+	{"mariadb", standardJDBCURL("mysql")},
+	{"postgres", standardJDBCURL("postgresql")},
+	{"sqlserver", sqlServerJDBCURL},
+	{"oracle", oracleJDBCURL},
+	{"db2", standardJDBCURL("db2")},
+}
+
+// standardJDBCURL returns a mapping to a jdbc:<scheme>://host:port/database?query URL.
+func standardJDBCURL(scheme string) func(u *url.URL) string {
+	return func(u *url.URL) string {
+		return withQuery(fmt.Sprintf("jdbc:%s://%s%s", scheme, u.Host, u.Path), "?", "&", u)
+	}
+}
+
+// oracleJDBCURL returns a jdbc:oracle:thin:@//host:port/service URL.
+func oracleJDBCURL(u *url.URL) string {
+	return withQuery(fmt.Sprintf("jdbc:oracle:thin:@//%s%s", u.Host, u.Path), "?", "&", u)
+}
+
+// sqlServerJDBCURL returns a jdbc:sqlserver://host:port;databaseName=database URL.  Query parameters are appended as
+// ;key=value properties.
+func sqlServerJDBCURL(u *url.URL) string {
+	s := fmt.Sprintf("jdbc:sqlserver://%s", u.Host)
+
+	if d := strings.TrimPrefix(u.Path, "/"); d != "" {
+		s = fmt.Sprintf("%s;databaseName=%s", s, d)
+	}
+
+	return withQuery(s, ";", ";", u)
+}
+
+func withQuery(s string, prefix string, separator string, u *url.URL) string {
+	if u.RawQuery == "" {
+		return s
+	}
+
+	return s + prefix + strings.ReplaceAll(u.RawQuery, "&", separator)
+}
+
+func database(service service, properties environment.Properties) {
+	if v, ok := service.string("jdbcUrl", "jdbc_url"); ok {
+		properties["spring.datasource.url"] = v
+	} else if u, ok := service.uri(); ok {
+		for _, j := range jdbcURLs {
+			if service.matches(j.candidate) {
+				properties["spring.datasource.url"] = j.url(u)
+				break
+			}
+		}
+
+		if u.User != nil {
+			properties["spring.datasource.username"] = u.User.Username()
+
+			if v, ok := u.User.Password(); ok {
+				properties["spring.datasource.password"] = v
+			}
+		}
+	}
+
+	if v, ok := service.string("username", "user"); ok {
+		properties["spring.datasource.username"] = v
+	}
+
+	if v, ok := service.string("password"); ok {
+		properties["spring.datasource.password"] = v
+	}
+}
+
+func mongodb(service service, properties environment.Properties) {
+	if u, ok := service.string("uri", "url"); ok {
+		properties["spring.data.mongodb.uri"] = u
+	}
+}
+
+func rabbitmq(service service, properties environment.Properties) {
+	if u, ok := service.uri(); ok {
+		properties["spring.rabbitmq.addresses"] = u.String()
+		properties["spring.rabbitmq.host"] = u.Hostname()
+
+		if v := u.Port(); v != "" {
+			properties["spring.rabbitmq.port"] = v
+		}
+
+		if u.User != nil {
+			properties["spring.rabbitmq.username"] = u.User.Username()
+
+			if v, ok := u.User.Password(); ok {
+				properties["spring.rabbitmq.password"] = v
+			}
+		}
+
+		if v := strings.TrimPrefix(u.Path, "/"); v != "" {
+			properties["spring.rabbitmq.virtual-host"] = v
+		}
+	}
+
+	if v, ok := service.string("hostname", "host"); ok {
+		properties["spring.rabbitmq.host"] = v
+	}
+
+	if v, ok := service.string("port"); ok {
+		properties["spring.rabbitmq.port"] = v
+	}
+
+	if v, ok := service.string("username", "user"); ok {
+		properties["spring.rabbitmq.username"] = v
+	}
+
+	if v, ok := service.string("password"); ok {
+		properties["spring.rabbitmq.password"] = v
+	}
+
+	if v, ok := service.string("vhost", "virtual_host"); ok {
+		properties["spring.rabbitmq.virtual-host"] = v
+	}
+}
+
+func redis(service service, properties environment.Properties) {
+	if u, ok := service.uri(); ok {
+		properties["spring.redis.url"] = u.String()
+	}
+
+	if v, ok := service.string("hostname", "host"); ok {
+		properties["spring.redis.host"] = v
+	}
+
+	if v, ok := service.string("port"); ok {
+		properties["spring.redis.port"] = v
+	}
+
+	if v, ok := service.string("password"); ok {
+		properties["spring.redis.password"] = v
+	}
+}
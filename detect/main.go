@@ -22,6 +22,7 @@ import (
 
 	"github.com/buildpacks/libbuildpack/v2/buildplan"
 	"github.com/cloudfoundry/libcfbuildpack/v2/detect"
+	"github.com/cloudfoundry/spring-boot-cnb/springboot"
 )
 
 func main() {
@@ -40,9 +41,18 @@ func main() {
 }
 
 func d(detect detect.Detect) (int, error) {
-	return detect.Pass(buildplan.Plan{
-		Requires: []buildplan.Required{
-			{Name: "jvm-application"},
-		},
-	})
+	requires := []buildplan.Required{
+		{Name: "jvm-application"},
+	}
+
+	if m, ok, err := springboot.NewJREModules(detect.Application, detect.Platform.Root, detect.Logger); err != nil {
+		return detect.Error(102), err
+	} else if ok {
+		requires = append(requires, buildplan.Required{
+			Name:     "jre",
+			Metadata: buildplan.Metadata{"launch": true, "modules": m},
+		})
+	}
+
+	return detect.Pass(buildplan.Plan{Requires: requires})
 }
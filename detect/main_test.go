@@ -17,6 +17,7 @@
 package main
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/buildpacks/libbuildpack/v2/buildplan"
@@ -46,5 +47,31 @@ func TestDetect(t *testing.T) {
 				},
 			}))
 		})
+
+		it("requires JRE modules", func() {
+			defer test.ReplaceEnv(t, "BP_SPRING_BOOT_JRE_MODULES", "true")()
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "META-INF", "MANIFEST.MF"),
+				`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+			test.CopyFile(t, filepath.Join("..", "springboot", "testdata", "test-xa-1.0.0.jar"),
+				filepath.Join(f.Detect.Application.Root, "test-lib", "test-xa-1.0.0.jar"))
+
+			g.Expect(d(f.Detect)).To(gomega.Equal(detect.PassStatusCode))
+			g.Expect(f.Plans).To(test.HavePlans(buildplan.Plan{
+				Requires: []buildplan.Required{
+					{Name: "jvm-application"},
+					{
+						Name: "jre",
+						Metadata: buildplan.Metadata{
+							"launch":  true,
+							"modules": []string{"java.base", "java.compiler", "java.transaction.xa", "jdk.crypto.ec"},
+						},
+					},
+				},
+			}))
+		})
 	}, spec.Report(report.Terminal{}))
 }
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package environment
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Properties are Spring Boot properties derived from the launch environment.
+type Properties map[string]string
+
+// Exports returns shell export statements for the properties, using their environment variable names.  Properties
+// whose environment variable is already set are not exported so that explicit configuration takes precedence.
+//
+// Properties with map keys in brackets (e.g. spring.security.oauth2.client.registration[My-Client].client-id) are
+// exported in $SPRING_APPLICATION_JSON instead, as relaxed binding lower-cases map keys bound from environment
+// variables and removes their dashes.  They are merged into an existing $SPRING_APPLICATION_JSON without overriding
+// its values and are not exported if it is not a JSON object.
+func (p Properties) Exports(lookup func(key string) (string, bool)) string {
+	var names []string
+	for n := range p {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	document := make(map[string]interface{})
+	keyed := false
+
+	for _, n := range names {
+		if strings.Contains(n, "[") {
+			document[n] = p[n]
+			keyed = true
+			continue
+		}
+
+		e := EnvironmentVariable(n)
+
+		if _, ok := lookup(e); ok {
+			continue
+		}
+
+		export(&b, e, p[n])
+	}
+
+	if keyed {
+		if s, ok := applicationJSON(document, lookup); ok {
+			export(&b, "SPRING_APPLICATION_JSON", s)
+		}
+	}
+
+	return b.String()
+}
+
+// applicationJSON merges properties into the $SPRING_APPLICATION_JSON document, returning false if the existing
+// document is not a JSON object.
+func applicationJSON(properties map[string]interface{}, lookup func(key string) (string, bool)) (string, bool) {
+	document := make(map[string]interface{})
+
+	if s, ok := lookup("SPRING_APPLICATION_JSON"); ok && strings.TrimSpace(s) != "" {
+		if err := json.Unmarshal([]byte(s), &document); err != nil {
+			return "", false
+		}
+	}
+
+	for k, v := range properties {
+		if _, ok := document[k]; !ok {
+			document[k] = v
+		}
+	}
+
+	b, err := json.Marshal(document)
+	if err != nil {
+		return "", false
+	}
+
+	return string(b), true
+}
+
+func export(b *strings.Builder, name string, value string) {
+	_, _ = fmt.Fprintf(b, "export %s='%s'\n", name, strings.ReplaceAll(value, "'", `'\''`))
+}
+
+// EnvironmentVariable returns the environment variable name that Spring Boot's relaxed binding maps to a property.
+func EnvironmentVariable(property string) string {
+	s := strings.ReplaceAll(property, "-", "")
+	s = strings.ReplaceAll(s, ".", "_")
+	return strings.ToUpper(s)
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package environment_test
+
+import (
+	"testing"
+
+	"github.com/cloudfoundry/spring-boot-cnb/environment"
+	"github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestProperties(t *testing.T) {
+	spec.Run(t, "Properties", func(t *testing.T, _ spec.G, it spec.S) {
+
+		g := gomega.NewWithT(t)
+
+		it("exports unset environment variables", func() {
+			p := environment.Properties{
+				"server.port":                  "8080",
+				"spring.datasource.password":   "test-'password",
+				"spring.rabbitmq.virtual-host": "test-vhost",
+			}
+
+			g.Expect(p.Exports(func(key string) (string, bool) {
+				return "", key == "SERVER_PORT"
+			})).To(gomega.Equal(`export SPRING_DATASOURCE_PASSWORD='test-'\''password'
+export SPRING_RABBITMQ_VIRTUALHOST='test-vhost'
+`))
+		})
+
+		it("exports properties with map keys in application JSON", func() {
+			p := environment.Properties{
+				"server.port": "8080",
+				"spring.security.oauth2.client.registration[My-Client].client-id": "test-client-id",
+			}
+
+			g.Expect(p.Exports(func(key string) (string, bool) {
+				return "", false
+			})).To(gomega.Equal(`export SERVER_PORT='8080'
+export SPRING_APPLICATION_JSON='{"spring.security.oauth2.client.registration[My-Client].client-id":"test-client-id"}'
+`))
+		})
+
+		it("merges properties with map keys into existing application JSON", func() {
+			p := environment.Properties{
+				"spring.security.oauth2.client.registration[alpha].client-id":     "test-client-id",
+				"spring.security.oauth2.client.registration[alpha].client-secret": "test-client-secret",
+			}
+
+			g.Expect(p.Exports(func(key string) (string, bool) {
+				if key == "SPRING_APPLICATION_JSON" {
+					return `{"server":{"port":8081},"spring.security.oauth2.client.registration[alpha].client-id":"explicit"}`, true
+				}
+				return "", false
+			})).To(gomega.Equal(`export SPRING_APPLICATION_JSON='{"server":{"port":8081},"spring.security.oauth2.client.registration[alpha].client-id":"explicit","spring.security.oauth2.client.registration[alpha].client-secret":"test-client-secret"}'
+`))
+		})
+
+		it("does not export properties with map keys when application JSON is invalid", func() {
+			p := environment.Properties{
+				"spring.security.oauth2.client.registration[alpha].client-id": "test-client-id",
+			}
+
+			g.Expect(p.Exports(func(key string) (string, bool) {
+				return "[1]", key == "SPRING_APPLICATION_JSON"
+			})).To(gomega.BeEmpty())
+		})
+
+		it("maps property names to environment variables", func() {
+			g.Expect(environment.EnvironmentVariable("spring.rabbitmq.virtual-host")).To(gomega.Equal("SPRING_RABBITMQ_VIRTUALHOST"))
+		})
+	}, spec.Report(report.Terminal{}))
+}
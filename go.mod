@@ -3,10 +3,13 @@ module github.com/cloudfoundry/spring-boot-cnb
 go 1.13
 
 require (
+	github.com/BurntSushi/toml v0.3.1
 	github.com/buildpacks/libbuildpack/v2 v2.0.7
 	github.com/cloudfoundry/libcfbuildpack/v2 v2.1.8
+	github.com/magiconair/properties v1.8.1
 	github.com/mattn/go-colorable v0.1.4 // indirect
 	github.com/mitchellh/mapstructure v1.1.2
 	github.com/onsi/gomega v1.9.0
 	github.com/sclevine/spec v1.4.0
+	gopkg.in/yaml.v2 v2.2.8
 )
@@ -0,0 +1,310 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package process
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/buildpacks/libbuildpack/v2/application"
+	"github.com/cloudfoundry/libcfbuildpack/v2/helper"
+	"github.com/cloudfoundry/libcfbuildpack/v2/layers"
+)
+
+const (
+	// File is the name of the file, in the application root, that configures process types.
+	File = "spring-boot.toml"
+
+	// Task is the type of the task process.
+	Task = "task"
+
+	// Web is the type of the web process.  It is the process type started when no other is specified at launch.
+	Web = "web"
+)
+
+var (
+	safe      = regexp.MustCompile(`^[\w@%+=:,./-]+$`)
+	validType = regexp.MustCompile(`^[\w-]+$`)
+)
+
+// Configuration is the user's configuration of the process types contributed for an application.
+type Configuration struct {
+	// Args are the arguments appended to the command of every process type.
+	Args []string `toml:"args"`
+
+	// Default is the process type that is started when no other is specified at launch.
+	Default string `toml:"default"`
+
+	// Processes are additional process types.
+	Processes []Process `toml:"processes"`
+
+	// Remove are the process types that should not be contributed.
+	Remove []string `toml:"remove"`
+}
+
+// Process is an additional process type.
+type Process struct {
+	// Type is the type of the process.
+	Type string `toml:"type"`
+
+	// Args are the arguments appended to the command of the process.
+	Args []string `toml:"args"`
+}
+
+// ProcessTypes returns the process types for a command.  The process type name is always contributed along with task
+// and web process types, followed by any configured process types.
+func (c Configuration) ProcessTypes(name string, command string) (layers.Processes, error) {
+	args := map[string][]string{name: nil, Task: nil, Web: nil}
+
+	for _, p := range c.Processes {
+		if _, ok := args[p.Type]; ok {
+			return nil, fmt.Errorf("process type %s is already defined", p.Type)
+		}
+
+		args[p.Type] = p.Args
+	}
+
+	for _, r := range c.Remove {
+		if r != Task && r != Web {
+			return nil, fmt.Errorf("process type %s cannot be removed, only %s and %s can be removed", r, Task, Web)
+		}
+
+		delete(args, r)
+	}
+
+	if c.Default != "" && c.Default != Web {
+		a, ok := args[c.Default]
+		if !ok {
+			return nil, fmt.Errorf("default process type %s is not defined", c.Default)
+		}
+
+		if _, ok := args[Web]; !ok {
+			return nil, fmt.Errorf("default process type %s cannot be set when %s is removed", c.Default, Web)
+		}
+
+		args[Web] = a
+	}
+
+	var processes layers.Processes
+	for t, a := range args {
+		processes = append(processes, layers.Process{Type: t, Command: c.command(command, a)})
+	}
+
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].Type < processes[j].Type
+	})
+
+	return processes, nil
+}
+
+// NamedProcessTypes returns a process type for each of several named commands, for applications that contain several
+// programs.  The arguments of a configured process type are appended to the command of the same name and the command
+// of the default process type is also contributed as the web process type.
+func (c Configuration) NamedProcessTypes(commands map[string]string) (layers.Processes, error) {
+	for _, p := range c.Processes {
+		if _, ok := commands[p.Type]; !ok {
+			return nil, fmt.Errorf("process type %s does not match a named command", p.Type)
+		}
+	}
+
+	web := true
+	for _, r := range c.Remove {
+		if r != Task && r != Web {
+			return nil, fmt.Errorf("process type %s cannot be removed, only %s and %s can be removed", r, Task, Web)
+		}
+
+		if r == Web {
+			web = false
+		}
+	}
+
+	var processes layers.Processes
+	for t, command := range commands {
+		processes = append(processes, layers.Process{Type: t, Command: c.command(command, c.args(t))})
+	}
+
+	if c.Default != "" {
+		command, ok := commands[c.Default]
+		if !ok {
+			return nil, fmt.Errorf("default process type %s is not defined", c.Default)
+		}
+
+		if !web {
+			return nil, fmt.Errorf("default process type %s cannot be set when %s is removed", c.Default, Web)
+		}
+
+		if _, ok := commands[Web]; ok && c.Default != Web {
+			return nil, fmt.Errorf("process type %s is already defined", Web)
+		}
+
+		if c.Default != Web {
+			processes = append(processes, layers.Process{Type: Web, Command: c.command(command, c.args(c.Default))})
+		}
+	}
+
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].Type < processes[j].Type
+	})
+
+	return processes, nil
+}
+
+// Add adds a process type for a command, with the configured arguments, to a collection of process types.
+func (c Configuration) Add(processes layers.Processes, t string, command string) (layers.Processes, error) {
+	for _, p := range processes {
+		if p.Type == t {
+			return nil, fmt.Errorf("process type %s is already defined", t)
+		}
+	}
+
+	processes = append(processes, layers.Process{Type: t, Command: c.command(command, nil)})
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].Type < processes[j].Type
+	})
+
+	return processes, nil
+}
+
+func (c Configuration) args(t string) []string {
+	for _, p := range c.Processes {
+		if p.Type == t {
+			return p.Args
+		}
+	}
+
+	return nil
+}
+
+func (c Configuration) command(command string, args []string) string {
+	s := append([]string{command}, quote(c.Args)...)
+	s = append(s, quote(args)...)
+	return strings.Join(s, " ")
+}
+
+// ValidType returns whether a process type only contains letters, numbers, '_', and '-'.
+func ValidType(t string) bool {
+	return validType.MatchString(t)
+}
+
+func (c Configuration) validate() error {
+	for _, p := range c.Processes {
+		if !validType.MatchString(p.Type) {
+			return fmt.Errorf("invalid process type %q, must only contain letters, numbers, '_', and '-'", p.Type)
+		}
+	}
+
+	for _, r := range c.Remove {
+		if !validType.MatchString(r) {
+			return fmt.Errorf("invalid process type %q to remove", r)
+		}
+	}
+
+	if c.Default != "" && !validType.MatchString(c.Default) {
+		return fmt.Errorf("invalid default process type %q", c.Default)
+	}
+
+	return nil
+}
+
+// NewConfiguration creates a new Configuration from the application's spring-boot.toml file overridden by the
+// BP_SPRING_BOOT_* environment variables.
+func NewConfiguration(application application.Application) (Configuration, error) {
+	c := Configuration{}
+
+	f := filepath.Join(application.Root, File)
+	if exists, err := helper.FileExists(f); err != nil {
+		return Configuration{}, err
+	} else if exists {
+		if _, err := toml.DecodeFile(f, &c); err != nil {
+			return Configuration{}, fmt.Errorf("unable to parse %s: %w", File, err)
+		}
+	}
+
+	if s, ok := os.LookupEnv("BP_SPRING_BOOT_ARGS"); ok {
+		c.Args = strings.Fields(s)
+	}
+
+	if s, ok := os.LookupEnv("BP_SPRING_BOOT_DEFAULT_PROCESS"); ok {
+		c.Default = strings.TrimSpace(s)
+	}
+
+	if s, ok := os.LookupEnv("BP_SPRING_BOOT_PROCESSES"); ok {
+		p, err := parseProcesses(s)
+		if err != nil {
+			return Configuration{}, err
+		}
+
+		c.Processes = p
+	}
+
+	if s, ok := os.LookupEnv("BP_SPRING_BOOT_REMOVE_PROCESSES"); ok {
+		c.Remove = split(s, ",")
+	}
+
+	if err := c.validate(); err != nil {
+		return Configuration{}, err
+	}
+
+	return c, nil
+}
+
+// parseProcesses parses process types in the form <type>=<args>[;<type>=<args>...].
+func parseProcesses(s string) ([]Process, error) {
+	var processes []Process
+
+	for _, d := range split(s, ";") {
+		p := strings.SplitN(d, "=", 2)
+		if len(p) != 2 || strings.TrimSpace(p[0]) == "" {
+			return nil, fmt.Errorf("invalid process type definition %q in BP_SPRING_BOOT_PROCESSES, must be <type>=<args>", d)
+		}
+
+		processes = append(processes, Process{Type: strings.TrimSpace(p[0]), Args: strings.Fields(p[1])})
+	}
+
+	return processes, nil
+}
+
+func quote(args []string) []string {
+	q := make([]string, len(args))
+
+	for i, a := range args {
+		if safe.MatchString(a) {
+			q[i] = a
+		} else {
+			q[i] = fmt.Sprintf("'%s'", strings.ReplaceAll(a, "'", `'\''`))
+		}
+	}
+
+	return q
+}
+
+func split(s string, separator string) []string {
+	var values []string
+
+	for _, v := range strings.Split(s, separator) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,252 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package process_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/layers"
+	"github.com/cloudfoundry/libcfbuildpack/v2/test"
+	"github.com/cloudfoundry/spring-boot-cnb/process"
+	"github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestConfiguration(t *testing.T) {
+	spec.Run(t, "Configuration", func(t *testing.T, when spec.G, it spec.S) {
+
+		g := gomega.NewWithT(t)
+
+		var f *test.BuildFactory
+
+		it.Before(func() {
+			f = test.NewBuildFactory(t)
+		})
+
+		when("NewConfiguration", func() {
+
+			it("returns empty configuration by default", func() {
+				g.Expect(process.NewConfiguration(f.Build.Application)).To(gomega.Equal(process.Configuration{}))
+			})
+
+			it("reads configuration from file", func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, process.File), `
+args    = ["--spring.profiles.active=production"]
+default = "worker"
+remove  = ["task"]
+
+[[processes]]
+type = "worker"
+args = ["--spring.main.web-application-type=none"]
+`)
+
+				g.Expect(process.NewConfiguration(f.Build.Application)).To(gomega.Equal(process.Configuration{
+					Args:    []string{"--spring.profiles.active=production"},
+					Default: "worker",
+					Processes: []process.Process{
+						{Type: "worker", Args: []string{"--spring.main.web-application-type=none"}},
+					},
+					Remove: []string{"task"},
+				}))
+			})
+
+			it("overrides file with environment variables", func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, process.File), `
+args    = ["--spring.profiles.active=production"]
+default = "worker"
+`)
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_ARGS", "--alpha --bravo")()
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_DEFAULT_PROCESS", "migrate")()
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_PROCESSES", "migrate=--charlie; worker=--delta --echo")()
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_REMOVE_PROCESSES", "task, web")()
+
+				g.Expect(process.NewConfiguration(f.Build.Application)).To(gomega.Equal(process.Configuration{
+					Args:    []string{"--alpha", "--bravo"},
+					Default: "migrate",
+					Processes: []process.Process{
+						{Type: "migrate", Args: []string{"--charlie"}},
+						{Type: "worker", Args: []string{"--delta", "--echo"}},
+					},
+					Remove: []string{"task", "web"},
+				}))
+			})
+
+			it("rejects malformed file", func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, process.File), "args = [")
+
+				_, err := process.NewConfiguration(f.Build.Application)
+				g.Expect(err).To(gomega.MatchError(gomega.HavePrefix("unable to parse spring-boot.toml")))
+			})
+
+			it("rejects malformed process definitions", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_PROCESSES", "--alpha")()
+
+				_, err := process.NewConfiguration(f.Build.Application)
+				g.Expect(err).To(gomega.MatchError(`invalid process type definition "--alpha" in BP_SPRING_BOOT_PROCESSES, must be <type>=<args>`))
+			})
+
+			it("rejects invalid process types", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_PROCESSES", "alpha bravo=--charlie")()
+
+				_, err := process.NewConfiguration(f.Build.Application)
+				g.Expect(err).To(gomega.MatchError(`invalid process type "alpha bravo", must only contain letters, numbers, '_', and '-'`))
+			})
+		})
+
+		when("ProcessTypes", func() {
+
+			it("returns default process types", func() {
+				g.Expect(process.Configuration{}.ProcessTypes("test-type", "test-command")).To(gomega.Equal(layers.Processes{
+					{Type: "task", Command: "test-command"},
+					{Type: "test-type", Command: "test-command"},
+					{Type: "web", Command: "test-command"},
+				}))
+			})
+
+			it("adds arguments and process types", func() {
+				c := process.Configuration{
+					Args:      []string{"--spring.profiles.active=production", "--alpha=bravo charlie"},
+					Processes: []process.Process{{Type: "worker", Args: []string{"--delta"}}},
+				}
+
+				g.Expect(c.ProcessTypes("test-type", "test-command")).To(gomega.Equal(layers.Processes{
+					{Type: "task", Command: "test-command --spring.profiles.active=production '--alpha=bravo charlie'"},
+					{Type: "test-type", Command: "test-command --spring.profiles.active=production '--alpha=bravo charlie'"},
+					{Type: "web", Command: "test-command --spring.profiles.active=production '--alpha=bravo charlie'"},
+					{Type: "worker", Command: "test-command --spring.profiles.active=production '--alpha=bravo charlie' --delta"},
+				}))
+			})
+
+			it("removes process types", func() {
+				c := process.Configuration{Remove: []string{"task", "web"}}
+
+				g.Expect(c.ProcessTypes("test-type", "test-command")).To(gomega.Equal(layers.Processes{
+					{Type: "test-type", Command: "test-command"},
+				}))
+			})
+
+			it("sets default process type", func() {
+				c := process.Configuration{
+					Default:   "worker",
+					Processes: []process.Process{{Type: "worker", Args: []string{"--alpha"}}},
+				}
+
+				g.Expect(c.ProcessTypes("test-type", "test-command")).To(gomega.Equal(layers.Processes{
+					{Type: "task", Command: "test-command"},
+					{Type: "test-type", Command: "test-command"},
+					{Type: "web", Command: "test-command --alpha"},
+					{Type: "worker", Command: "test-command --alpha"},
+				}))
+			})
+
+			it("adds process type with arguments", func() {
+				c := process.Configuration{Args: []string{"--alpha"}}
+
+				p, err := c.ProcessTypes("test-type", "test-command")
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(c.Add(p, "bravo", "other-command")).To(gomega.Equal(layers.Processes{
+					{Type: "bravo", Command: "other-command --alpha"},
+					{Type: "task", Command: "test-command --alpha"},
+					{Type: "test-type", Command: "test-command --alpha"},
+					{Type: "web", Command: "test-command --alpha"},
+				}))
+			})
+
+			it("rejects adding duplicate process type", func() {
+				c := process.Configuration{Processes: []process.Process{{Type: "bravo"}}}
+
+				p, err := c.ProcessTypes("test-type", "test-command")
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				_, err = c.Add(p, "bravo", "other-command")
+				g.Expect(err).To(gomega.MatchError("process type bravo is already defined"))
+			})
+
+			it("rejects duplicate process types", func() {
+				c := process.Configuration{Processes: []process.Process{{Type: "web"}}}
+
+				_, err := c.ProcessTypes("test-type", "test-command")
+				g.Expect(err).To(gomega.MatchError("process type web is already defined"))
+			})
+
+			it("rejects removing other process types", func() {
+				c := process.Configuration{Remove: []string{"test-type"}}
+
+				_, err := c.ProcessTypes("test-type", "test-command")
+				g.Expect(err).To(gomega.MatchError("process type test-type cannot be removed, only task and web can be removed"))
+			})
+
+			it("rejects undefined default process type", func() {
+				c := process.Configuration{Default: "worker"}
+
+				_, err := c.ProcessTypes("test-type", "test-command")
+				g.Expect(err).To(gomega.MatchError("default process type worker is not defined"))
+			})
+
+			it("rejects default process type when web is removed", func() {
+				c := process.Configuration{Default: "task", Remove: []string{"web"}}
+
+				_, err := c.ProcessTypes("test-type", "test-command")
+				g.Expect(err).To(gomega.MatchError("default process type task cannot be set when web is removed"))
+			})
+		})
+
+		when("NamedProcessTypes", func() {
+
+			commands := map[string]string{"alpha": "alpha-command", "bravo": "bravo-command"}
+
+			it("returns process type for each command", func() {
+				c := process.Configuration{
+					Args:      []string{"--charlie"},
+					Processes: []process.Process{{Type: "bravo", Args: []string{"--delta"}}},
+				}
+
+				g.Expect(c.NamedProcessTypes(commands)).To(gomega.Equal(layers.Processes{
+					{Type: "alpha", Command: "alpha-command --charlie"},
+					{Type: "bravo", Command: "bravo-command --charlie --delta"},
+				}))
+			})
+
+			it("sets web process type to default process type", func() {
+				c := process.Configuration{Default: "bravo"}
+
+				g.Expect(c.NamedProcessTypes(commands)).To(gomega.Equal(layers.Processes{
+					{Type: "alpha", Command: "alpha-command"},
+					{Type: "bravo", Command: "bravo-command"},
+					{Type: "web", Command: "bravo-command"},
+				}))
+			})
+
+			it("rejects process types that do not match a command", func() {
+				c := process.Configuration{Processes: []process.Process{{Type: "charlie"}}}
+
+				_, err := c.NamedProcessTypes(commands)
+				g.Expect(err).To(gomega.MatchError("process type charlie does not match a named command"))
+			})
+
+			it("rejects undefined default process type", func() {
+				c := process.Configuration{Default: "charlie"}
+
+				_, err := c.NamedProcessTypes(commands)
+				g.Expect(err).To(gomega.MatchError("default process type charlie is not defined"))
+			})
+		})
+	}, spec.Report(report.Terminal{}))
+}
@@ -8,4 +8,6 @@ if [[ -d $PWD/go-module-cache && ! -d ${GOPATH}/pkg/mod ]]; then
 fi
 
 GOOS="linux" go build -ldflags='-s -w' -o bin/build build/main.go
+GOOS="linux" go build -ldflags='-s -w' -o bin/cloud-foundry-properties cloud-foundry-properties/main.go
 GOOS="linux" go build -ldflags='-s -w' -o bin/detect detect/main.go
+GOOS="linux" go build -ldflags='-s -w' -o bin/service-binding-properties service-binding-properties/main.go
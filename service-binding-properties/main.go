@@ -0,0 +1,34 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cloudfoundry/spring-boot-cnb/bindings"
+)
+
+func main() {
+	p, err := bindings.NewProperties(os.Getenv("SERVICE_BINDING_ROOT"))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Failed to map service bindings: %s\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Print(p.Exports(os.LookupEnv))
+}
@@ -0,0 +1,216 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cloudfoundry/spring-boot-cnb/process"
+)
+
+// artifactName matches the file name of an artifact, capturing the name without a version.
+var artifactName = regexp.MustCompile(`^(.+?)(-[0-9].*)?\.(jar|war)$`)
+
+// artifact is a Spring Boot jar or war that has not been exploded into the application root.
+type artifact struct {
+	// Name is the process type of the artifact when an application contains multiple artifacts.
+	Name string
+
+	// Path is the path of the artifact relative to the application root.
+	Path string
+
+	// MainClass is the Main-Class of the artifact, the Spring Boot launcher.
+	MainClass string
+
+	manifest map[string]string
+}
+
+// packaging describes the Spring Boot artifacts of an application that has not been exploded.  Artifacts are either
+// the boot jar of a distribution or the artifacts in the application root.
+type packaging struct {
+	// Artifacts are the Spring Boot artifacts of the application.
+	Artifacts []artifact
+
+	// Lib is the directory, relative to the application root, containing the boot jar of a distribution.
+	Lib string
+
+	// Script is the path of the distribution's start script relative to the application root.  It is empty if the
+	// application is not a distribution or the start script cannot be determined.
+	Script string
+}
+
+func (p packaging) contains(path string) bool {
+	for _, a := range p.Artifacts {
+		if a.Path == path {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p packaging) isMultiple() bool {
+	return len(p.Artifacts) > 1
+}
+
+// newArtifact creates a new artifact from a jar or war.  OK is false if its manifest does not declare
+// Spring-Boot-Version.
+func newArtifact(root string, path string) (artifact, bool, error) {
+	m, err := jarManifest(path)
+	if err != nil {
+		return artifact{}, false, err
+	}
+
+	if _, ok := m["Spring-Boot-Version"]; !ok {
+		return artifact{}, false, nil
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return artifact{}, false, err
+	}
+
+	if m["Main-Class"] == "" {
+		return artifact{}, false, fmt.Errorf("%s does not declare a Main-Class", rel)
+	}
+
+	n := filepath.Base(path)
+	if g := artifactName.FindStringSubmatch(n); g != nil {
+		n = g[1]
+	}
+
+	return artifact{Name: n, Path: rel, MainClass: m["Main-Class"], manifest: m}, true, nil
+}
+
+// newRootArtifacts creates a new packaging from the Spring Boot artifacts in the application root, limited to those
+// matching $BP_SPRING_BOOT_ARTIFACT if it is set.  OK is false if there are no artifacts.
+func newRootArtifacts(root string) (packaging, bool, error) {
+	pattern, selected := os.LookupEnv("BP_SPRING_BOOT_ARTIFACT")
+	if selected {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return packaging{}, false, fmt.Errorf("invalid $BP_SPRING_BOOT_ARTIFACT: %s", pattern)
+		}
+	}
+
+	artifacts, err := findRootArtifacts(root, pattern)
+	if err != nil {
+		return packaging{}, false, err
+	}
+
+	p := packaging{}
+	names := make(map[string]string)
+	for _, a := range artifacts {
+		if other, ok := names[a.Name]; ok {
+			return packaging{}, false, fmt.Errorf(
+				"Spring Boot artifacts %s and %s both map to process type %s, set $BP_SPRING_BOOT_ARTIFACT to select one",
+				other, a.Path, a.Name)
+		}
+		names[a.Name] = a.Path
+
+		p.Artifacts = append(p.Artifacts, a)
+	}
+
+	if p.isMultiple() {
+		for _, a := range p.Artifacts {
+			if !process.ValidType(a.Name) {
+				return packaging{}, false, fmt.Errorf("Spring Boot artifact %s maps to invalid process type %q, must "+
+					"only contain letters, numbers, '_', and '-', set $BP_SPRING_BOOT_ARTIFACT to select one", a.Path, a.Name)
+			}
+		}
+	}
+
+	if selected {
+		switch len(p.Artifacts) {
+		case 0:
+			return packaging{}, false, fmt.Errorf(
+				"no Spring Boot artifact in the application root matches $BP_SPRING_BOOT_ARTIFACT %s", pattern)
+		case 1:
+		default:
+			return packaging{}, false, fmt.Errorf("$BP_SPRING_BOOT_ARTIFACT %s matches multiple Spring Boot artifacts: %s",
+				pattern, artifactPaths(p.Artifacts))
+		}
+	}
+
+	return p, len(p.Artifacts) > 0, nil
+}
+
+// findRootArtifacts returns the Spring Boot artifacts in the application root whose file names match a pattern, or all
+// of them if the pattern is empty, sorted by path.
+func findRootArtifacts(root string, pattern string) ([]artifact, error) {
+	var candidates []string
+	for _, g := range []string{"*.jar", "*.war"} {
+		c, err := filepath.Glob(filepath.Join(root, g))
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c...)
+	}
+	sort.Strings(candidates)
+
+	var artifacts []artifact
+	for _, c := range candidates {
+		if pattern != "" {
+			if ok, _ := filepath.Match(pattern, filepath.Base(c)); !ok {
+				continue
+			}
+		}
+
+		a, ok, err := newArtifact(root, c)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+
+		artifacts = append(artifacts, a)
+	}
+
+	return artifacts, nil
+}
+
+// artifactPaths returns the comma separated paths of artifacts.
+func artifactPaths(artifacts []artifact) string {
+	var paths []string
+	for _, a := range artifacts {
+		paths = append(paths, a.Path)
+	}
+
+	return strings.Join(paths, ", ")
+}
+
+// jarManifest returns the main section of a jar's manifest.
+func jarManifest(path string) (map[string]string, error) {
+	z, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %s: %w", path, err)
+	}
+	defer z.Close()
+
+	for _, f := range z.File {
+		if f.Name == "META-INF/MANIFEST.MF" {
+			return readManifest(f)
+		}
+	}
+
+	return map[string]string{}, nil
+}
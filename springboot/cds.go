@@ -0,0 +1,158 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/layers"
+)
+
+// CDSDependency is the name of the layer containing the class data sharing archive.
+const CDSDependency = "class-data-sharing"
+
+const (
+	// cdsReleaseLine is the first Spring Boot release line whose Spring Framework supports
+	// -Dspring.context.exit=onRefresh.  Earlier versions never exit the training run.
+	cdsReleaseLine = "3.2"
+
+	archiveClassesAtExit = "ArchiveClassesAtExit"
+)
+
+var archiveClassesAtExitFlag = regexp.MustCompile(`\b` + archiveClassesAtExit + `\b`)
+
+// CDS is the configuration of generating an AppCDS archive with a training run.
+type CDS struct {
+	// Enabled indicates whether an AppCDS archive is generated.
+	Enabled bool
+
+	// Java is the path to the java executable used for the training run.  Empty if no JVM is available.
+	Java string
+}
+
+// NewCDS creates a new CDS from the $BP_SPRING_BOOT_CDS environment variable.  If enabled, the training run uses the
+// java on $PATH, typically contributed by a preceding buildpack.
+func NewCDS() (CDS, error) {
+	c := CDS{}
+
+	var err error
+	if c.Enabled, err = boolEnv("BP_SPRING_BOOT_CDS"); err != nil {
+		return CDS{}, err
+	}
+
+	if c.Enabled {
+		if p, err := exec.LookPath("java"); err == nil {
+			c.Java = p
+		}
+	}
+
+	return c, nil
+}
+
+// cdsMetadata identifies an archive.  The JVM is included because a JVM rejects archives created by any other JVM.
+type cdsMetadata struct {
+	Digest      string `toml:"digest"`
+	Java        string `toml:"java"`
+	JavaVersion string `toml:"java-version"`
+	StartClass  string `toml:"start-class"`
+}
+
+func (c cdsMetadata) Identity() (string, string) {
+	return "Class Data Sharing Archive", ""
+}
+
+// contributeCDS generates an AppCDS archive by starting the application with -XX:ArchiveClassesAtExit and exiting
+// after the application context has been refreshed.  The archive is contributed to a layer marked launch and added to
+// $JAVA_OPTS with -XX:SharedArchiveFile, and is regenerated when the JVM changes.  No archive is generated for Spring
+// Boot versions whose training run would not exit or for JVMs that do not support -XX:ArchiveClassesAtExit.
+func (s SpringBoot) contributeCDS() error {
+	if s.cds.Java == "" {
+		s.logger.HeaderWarning("Unable to generate class data sharing archive, java is not on $PATH")
+		return nil
+	}
+
+	if c, err := compareReleaseLines(releaseLine(s.Metadata.Version), cdsReleaseLine); err != nil || c < 0 {
+		s.logger.HeaderWarning("Unable to generate class data sharing archive, Spring Boot %s or later is required",
+			cdsReleaseLine)
+		return nil
+	}
+
+	if ok, err := s.supportsArchiveClassesAtExit(); err != nil {
+		return err
+	} else if !ok {
+		s.logger.HeaderWarning("Unable to generate class data sharing archive, %s does not support -XX:%s",
+			s.cds.Java, archiveClassesAtExit)
+		return nil
+	}
+
+	d, err := s.digest()
+	if err != nil {
+		return err
+	}
+
+	v, err := s.runner.RunWithOutput(s.cds.Java, s.application.Root, "-version")
+	if err != nil {
+		return fmt.Errorf("unable to determine version of %s: %w\n%s", s.cds.Java, err, v)
+	}
+
+	md := cdsMetadata{
+		Digest:      d,
+		Java:        s.cds.Java,
+		JavaVersion: strings.TrimSpace(string(v)),
+		StartClass:  s.mainClass(),
+	}
+
+	return s.layers.Layer(CDSDependency).Contribute(md, func(layer layers.Layer) error {
+		if err := os.RemoveAll(layer.Root); err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(layer.Root, 0755); err != nil {
+			return err
+		}
+
+		archive := filepath.Join(layer.Root, "application.jsa")
+
+		layer.Logger.Body("Starting %s to train class data sharing archive", s.mainClass())
+		if err := s.runner.Run(s.cds.Java, s.application.Root,
+			fmt.Sprintf("-XX:ArchiveClassesAtExit=%s", archive),
+			"-Dspring.context.exit=onRefresh",
+			"-cp", strings.Join(s.Metadata.ClassPath, string(filepath.ListSeparator)),
+			s.mainClass(),
+		); err != nil {
+			return fmt.Errorf("unable to generate class data sharing archive: %w", err)
+		}
+
+		return layer.AppendLaunchEnv("JAVA_OPTS", " -XX:SharedArchiveFile=%s", archive)
+	}, layers.Launch)
+}
+
+// supportsArchiveClassesAtExit returns whether the JVM used for the training run supports -XX:ArchiveClassesAtExit,
+// which was added in Java 13.
+func (s SpringBoot) supportsArchiveClassesAtExit() (bool, error) {
+	b, err := s.runner.RunWithOutput(s.cds.Java, s.application.Root, "-XX:+PrintFlagsFinal", "-version")
+	if err != nil {
+		return false, fmt.Errorf("unable to determine flags of %s: %w\n%s", s.cds.Java, err, b)
+	}
+
+	return archiveClassesAtExitFlag.Match(b), nil
+}
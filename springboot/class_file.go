@@ -0,0 +1,217 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// constant is an entry of a class file's constant pool.  Value is the string of a CONSTANT_Utf8 entry and Index is the
+// first index referenced by other entries.
+type constant struct {
+	Tag   byte
+	Value string
+	Index uint16
+}
+
+// constantPool is the constant pool of a class file.
+type constantPool []constant
+
+func (p constantPool) utf8(i uint16) string {
+	if int(i) < len(p) && p[i].Tag == 1 {
+		return p[i].Value
+	}
+
+	return ""
+}
+
+// constantStrings returns the CONSTANT_Utf8 entries of a class file's constant pool.  These include the names and
+// descriptors of every class referenced by the class.
+func constantStrings(r io.Reader) ([]string, error) {
+	pool, err := readConstantPool(bufio.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+
+	var s []string
+	for _, c := range pool {
+		if c.Tag == 1 {
+			s = append(s, c.Value)
+		}
+	}
+
+	return s, nil
+}
+
+// moduleName returns the name of the module described by a module-info.class file.
+func moduleName(r io.Reader) (string, error) {
+	b := bufio.NewReader(r)
+
+	pool, err := readConstantPool(b)
+	if err != nil {
+		return "", err
+	}
+
+	// access_flags, this_class, super_class
+	if _, err := io.CopyN(ioutil.Discard, b, 6); err != nil {
+		return "", err
+	}
+
+	n, err := readUint16(b)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.CopyN(ioutil.Discard, b, int64(n)*2); err != nil {
+		return "", err
+	}
+
+	// fields and methods
+	for i := 0; i < 2; i++ {
+		n, err := readUint16(b)
+		if err != nil {
+			return "", err
+		}
+
+		for j := uint16(0); j < n; j++ {
+			if _, err := io.CopyN(ioutil.Discard, b, 6); err != nil {
+				return "", err
+			}
+
+			if err := readAttributes(b, pool, func(string, []byte) {}); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	var name string
+	if err := readAttributes(b, pool, func(n string, info []byte) {
+		if n != "Module" || len(info) < 2 {
+			return
+		}
+
+		if i := binary.BigEndian.Uint16(info); int(i) < len(pool) && pool[i].Tag == 19 {
+			name = pool.utf8(pool[i].Index)
+		}
+	}); err != nil {
+		return "", err
+	}
+
+	if name == "" {
+		return "", fmt.Errorf("no Module attribute found")
+	}
+
+	return name, nil
+}
+
+// readAttributes reads the attributes of a class file structure, calling a function with the name and contents of each.
+func readAttributes(b *bufio.Reader, pool constantPool, f func(name string, info []byte)) error {
+	n, err := readUint16(b)
+	if err != nil {
+		return err
+	}
+
+	for i := uint16(0); i < n; i++ {
+		var a struct {
+			Name   uint16
+			Length uint32
+		}
+		if err := binary.Read(b, binary.BigEndian, &a); err != nil {
+			return err
+		}
+
+		info := make([]byte, a.Length)
+		if _, err := io.ReadFull(b, info); err != nil {
+			return err
+		}
+
+		f(pool.utf8(a.Name), info)
+	}
+
+	return nil
+}
+
+// readConstantPool reads the header and constant pool of a class file.  The pool is indexed from 1 and the second
+// entry of a Long or Double is empty.
+func readConstantPool(b *bufio.Reader) (constantPool, error) {
+	var header struct {
+		Magic uint32
+		Minor uint16
+		Major uint16
+		Count uint16
+	}
+	if err := binary.Read(b, binary.BigEndian, &header); err != nil {
+		return nil, err
+	}
+
+	if header.Magic != 0xCAFEBABE {
+		return nil, fmt.Errorf("invalid class file magic %x", header.Magic)
+	}
+
+	pool := make(constantPool, header.Count)
+	for i := uint16(1); i < header.Count; i++ {
+		tag, err := b.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		pool[i].Tag = tag
+
+		var skip int64
+		switch tag {
+		case 1: // Utf8
+			n, err := readUint16(b)
+			if err != nil {
+				return nil, err
+			}
+
+			u := make([]byte, n)
+			if _, err := io.ReadFull(b, u); err != nil {
+				return nil, err
+			}
+			pool[i].Value = string(u)
+		case 7, 8, 16, 19, 20: // Class, String, MethodType, Module, Package
+			if pool[i].Index, err = readUint16(b); err != nil {
+				return nil, err
+			}
+		case 15: // MethodHandle
+			skip = 3
+		case 3, 4, 9, 10, 11, 12, 17, 18:
+			// Integer, Float, Fieldref, Methodref, InterfaceMethodref, NameAndType, Dynamic, InvokeDynamic
+			skip = 4
+		case 5, 6: // Long, Double take two entries
+			skip = 8
+			i++
+		default:
+			return nil, fmt.Errorf("invalid constant pool tag %d", tag)
+		}
+
+		if _, err := io.CopyN(ioutil.Discard, b, skip); err != nil {
+			return nil, err
+		}
+	}
+
+	return pool, nil
+}
+
+func readUint16(r io.Reader) (uint16, error) {
+	var n uint16
+	err := binary.Read(r, binary.BigEndian, &n)
+	return n, err
+}
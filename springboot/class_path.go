@@ -0,0 +1,145 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/helper"
+	"github.com/cloudfoundry/libcfbuildpack/v2/logger"
+	"github.com/magiconair/properties"
+)
+
+// propertiesLaunchers are the Main-Class of the Spring Boot launcher that honors loader.path, before and after
+// Spring Boot 3.2.
+var propertiesLaunchers = map[string]bool{
+	"org.springframework.boot.loader.PropertiesLauncher":        true,
+	"org.springframework.boot.loader.launch.PropertiesLauncher": true,
+}
+
+// loaderPath returns the entries of loader.path in the loader.properties at the root of an application's classes,
+// falling back to the Loader-Path manifest key.  Only PropertiesLauncher honors loader.path, so no entries are
+// returned for any other Main-Class.
+func loaderPath(root string, classes string, mainClass string, manifest string) ([]string, error) {
+	if !propertiesLaunchers[mainClass] {
+		return nil, nil
+	}
+
+	s := manifest
+
+	f := filepath.Join(root, classes, "loader.properties")
+	if exists, err := helper.FileExists(f); err != nil {
+		return nil, err
+	} else if exists {
+		p, err := properties.LoadFile(f, properties.UTF8)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", f, err)
+		}
+
+		s = p.GetString("loader.path", s)
+	}
+
+	var entries []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries, nil
+}
+
+// manifestClassPath returns the entries of a Class-Path manifest key, decoding their URL escapes.  Entries that are not
+// valid URL paths are skipped.
+func manifestClassPath(classPath string, logger logger.Logger) []string {
+	var entries []string
+
+	for _, e := range strings.Fields(classPath) {
+		d, err := url.PathUnescape(e)
+		if err != nil {
+			logger.Debug("Skipping classpath entry %s that is not a valid URL: %s", e, err)
+			continue
+		}
+
+		entries = append(entries, d)
+	}
+
+	return entries
+}
+
+// resolveClassPath resolves Class-Path and loader.path entries relative to the application root, returning the paths
+// of the entries relative to the root and the classpath they contribute.  A directory in loader.path contributes
+// itself followed by the jars in it.  Entries that are outside of the application, use placeholders, or do not exist
+// are skipped.
+func resolveClassPath(root string, entries []string, expand bool, logger logger.Logger) ([]string, []string, error) {
+	var rel, classPath []string
+
+	for _, e := range entries {
+		if strings.Contains(e, "${") || strings.Contains(e, ":") || strings.Contains(e, "!") {
+			logger.Debug("Skipping classpath entry %s that cannot be resolved during build", e)
+			continue
+		}
+
+		p := filepath.Join(root, filepath.FromSlash(e))
+		if filepath.IsAbs(filepath.FromSlash(e)) || !strings.HasPrefix(p, filepath.Clean(root)+string(filepath.Separator)) {
+			logger.Debug("Skipping classpath entry %s outside of the application", e)
+			continue
+		}
+
+		i, err := os.Stat(p)
+		if os.IsNotExist(err) {
+			logger.Debug("Skipping classpath entry %s that does not exist", e)
+			continue
+		} else if err != nil {
+			return nil, nil, err
+		}
+
+		r, err := filepath.Rel(root, p)
+		if err != nil {
+			return nil, nil, err
+		}
+		rel = append(rel, filepath.ToSlash(r))
+		classPath = append(classPath, p)
+
+		if !expand || !i.IsDir() {
+			continue
+		}
+
+		c, err := ioutil.ReadDir(p)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var jars []string
+		for _, f := range c {
+			if !f.IsDir() && filepath.Ext(f.Name()) == ".jar" {
+				jars = append(jars, filepath.Join(p, f.Name()))
+			}
+		}
+		sort.Strings(jars)
+
+		classPath = append(classPath, jars...)
+	}
+
+	return rel, classPath, nil
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/logger"
+)
+
+var (
+	// bootModule matches the names of Spring Boot's own modules.
+	bootModule = regexp.MustCompile(`^spring-boot(-(actuator|autoconfigure|configuration-processor|devtools|` +
+		`docker-compose|jarmode|loader|properties-migrator|starter|test|testcontainers)[\w-]*)?$`)
+
+	// frameworkModule matches the names of Spring Framework's modules.
+	frameworkModule = regexp.MustCompile(`^spring-(aop|aspects|beans|context|context-indexer|context-support|core|` +
+		`expression|instrument|jcl|jdbc|jms|messaging|orm|oxm|r2dbc|test|tx|web|webflux|webmvc|websocket)$`)
+
+	// frameworkVersions are the Spring Framework release lines managed by each Spring Boot release line.
+	frameworkVersions = map[string]string{
+		"1.5": "4.3",
+		"2.0": "5.0",
+		"2.1": "5.1",
+		"2.2": "5.2",
+		"2.3": "5.2",
+		"2.4": "5.3",
+		"2.5": "5.3",
+		"2.6": "5.3",
+		"2.7": "5.3",
+		"3.0": "6.0",
+		"3.1": "6.0",
+		"3.2": "6.1",
+		"3.3": "6.1",
+		"3.4": "6.2",
+		"3.5": "6.2",
+		"4.0": "7.0",
+	}
+
+	releaseLinePattern = regexp.MustCompile(`^(\d+)\.(\d+)`)
+)
+
+// VersionPolicy is the handling of Spring dependencies that do not match the Spring Boot version.
+type VersionPolicy struct {
+	// Fail indicates whether the build fails if any mismatches are found.
+	Fail bool
+}
+
+// NewVersionPolicy creates a new VersionPolicy from the $BP_SPRING_BOOT_FAIL_ON_VERSION_MISMATCH environment
+// variable.
+func NewVersionPolicy() (VersionPolicy, error) {
+	f, err := boolEnv("BP_SPRING_BOOT_FAIL_ON_VERSION_MISMATCH")
+	if err != nil {
+		return VersionPolicy{}, err
+	}
+
+	return VersionPolicy{Fail: f}, nil
+}
+
+// mismatch is a Spring dependency whose version does not match the version managed by the Spring Boot release.
+type mismatch struct {
+	Dependency JARDependency
+	Expected   string
+}
+
+// logMismatches reports version mismatches.
+func logMismatches(mismatches []mismatch, version string, logger logger.Logger) {
+	if len(mismatches) == 0 {
+		return
+	}
+
+	logger.HeaderWarning("Found Spring dependencies that do not match Spring Boot %s", version)
+	for _, m := range mismatches {
+		logger.BodyWarning("%s %s, expected %s", m.Dependency.Name, m.Dependency.Version, m.Expected)
+	}
+}
+
+// versionMismatches returns the Spring Boot dependencies whose version is not the Spring Boot version and the Spring
+// Framework dependencies whose release line is not the one managed by the Spring Boot release line.  Spring Framework
+// dependencies are not checked for unknown Spring Boot release lines.
+func versionMismatches(version string, dependencies JARDependencies) []mismatch {
+	var m []mismatch
+
+	framework, known := frameworkVersions[releaseLine(version)]
+
+	for _, d := range dependencies {
+		if bootModule.MatchString(d.Name) && d.Version != version {
+			m = append(m, mismatch{Dependency: d, Expected: version})
+		} else if known && frameworkModule.MatchString(d.Name) && releaseLine(d.Version) != framework {
+			m = append(m, mismatch{Dependency: d, Expected: fmt.Sprintf("%s.x", framework)})
+		}
+	}
+
+	return m
+}
+
+// releaseLine returns the <major>.<minor> release line of a version, or an empty string if it cannot be determined.
+func releaseLine(version string) string {
+	if g := releaseLinePattern.FindStringSubmatch(version); g != nil {
+		return strings.Join(g[1:], ".")
+	}
+
+	return ""
+}
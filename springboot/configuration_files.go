@@ -0,0 +1,116 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// configurationFile matches the name of a Spring configuration file, capturing the profile of a profile-specific
+	// file.
+	configurationFile = regexp.MustCompile(`^(?:application|bootstrap)(?:-(.+?))?\.(properties|yml|yaml)$`)
+
+	// configurationError matches the line and message of a properties or YAML syntax error.
+	configurationError = regexp.MustCompile(`(?is)^(?:properties|yaml):(?: unmarshal errors:\s*)? line (\d+): (.*)$`)
+)
+
+// configurationFiles are the Spring configuration files in Spring-Boot-Classes.
+type configurationFiles struct {
+	// Profiles are the profiles of the profile-specific files.
+	Profiles []string
+}
+
+// newConfigurationFiles finds the application*.properties, application*.yml, bootstrap*.properties, and bootstrap*.yml
+// files in the root and config/ directory of Spring-Boot-Classes and validates their syntax.  Every syntax error is
+// returned, with the file and line it occurs on, so that invalid configuration fails the build instead of the
+// application at launch.
+func newConfigurationFiles(root string, classes string) (configurationFiles, error) {
+	c := configurationFiles{}
+
+	if ok, err := isClassesDirectory(root, classes); err != nil {
+		return configurationFiles{}, err
+	} else if !ok {
+		return c, nil
+	}
+
+	profiles := make(map[string]bool)
+	var invalid []string
+
+	// Spring Boot only reads configuration files from the root of the classpath and its config/ directory.
+	for _, d := range []string{filepath.Join(root, classes), filepath.Join(root, classes, "config")} {
+		files, err := ioutil.ReadDir(d)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return configurationFiles{}, err
+		}
+
+		for _, f := range files {
+			m := configurationFile.FindStringSubmatch(f.Name())
+			if f.IsDir() || m == nil {
+				continue
+			}
+
+			if m[1] != "" {
+				profiles[m[1]] = true
+			}
+
+			path := filepath.Join(d, f.Name())
+
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return configurationFiles{}, err
+			}
+
+			var invalidErr error
+			if m[2] == "properties" {
+				_, invalidErr = readPropertiesFile(path)
+			} else {
+				_, invalidErr = readYAMLFile(path)
+			}
+
+			if invalidErr == nil {
+				continue
+			}
+
+			if e := configurationError.FindStringSubmatch(invalidErr.Error()); e != nil {
+				invalid = append(invalid, fmt.Sprintf("%s:%s: %s", rel, e[1], strings.TrimSpace(e[2])))
+			} else {
+				invalid = append(invalid, fmt.Sprintf("%s: %s", rel, invalidErr))
+			}
+		}
+	}
+
+	if len(invalid) > 0 {
+		return configurationFiles{}, fmt.Errorf("found %d invalid Spring configuration files:\n  %s",
+			len(invalid), strings.Join(invalid, "\n  "))
+	}
+
+	for p := range profiles {
+		c.Profiles = append(c.Profiles, p)
+	}
+	sort.Strings(c.Profiles)
+
+	return c, nil
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/logger"
+)
+
+// ConflictPolicy is the handling of conflicts within an application's dependencies.
+type ConflictPolicy struct {
+	// Fail indicates whether the build fails if any conflicts are found.
+	Fail bool
+}
+
+// NewConflictPolicy creates a new ConflictPolicy from the $BP_SPRING_BOOT_FAIL_ON_CONFLICTS environment variable.
+func NewConflictPolicy() (ConflictPolicy, error) {
+	f, err := boolEnv("BP_SPRING_BOOT_FAIL_ON_CONFLICTS")
+	if err != nil {
+		return ConflictPolicy{}, err
+	}
+
+	return ConflictPolicy{Fail: f}, nil
+}
+
+// conflicts are the duplicate artifacts and split packages within an application's dependencies.
+type conflicts struct {
+	// Duplicates are dependencies with the same name but different versions.
+	Duplicates []duplicate
+
+	// SplitPackages are pairs of differently named dependencies that contain the same classes.
+	SplitPackages []splitPackage
+}
+
+// duplicate is a dependency name that appears with more than one version.
+type duplicate struct {
+	Name     string
+	Versions []string
+}
+
+// splitPackage is a pair of dependencies that contain the same classes.
+type splitPackage struct {
+	Dependencies [2]string
+	Classes      int
+	Packages     []string
+}
+
+// Len returns the number of conflicts.
+func (c conflicts) Len() int {
+	return len(c.Duplicates) + len(c.SplitPackages)
+}
+
+// Log reports the conflicts.
+func (c conflicts) Log(logger logger.Logger) {
+	if len(c.Duplicates) > 0 {
+		logger.HeaderWarning("Found duplicate dependencies in Spring-Boot-Lib")
+		for _, d := range c.Duplicates {
+			logger.BodyWarning("%s: %s", d.Name, strings.Join(d.Versions, ", "))
+		}
+	}
+
+	if len(c.SplitPackages) > 0 {
+		logger.HeaderWarning("Found classes in more than one dependency in Spring-Boot-Lib")
+		for _, s := range c.SplitPackages {
+			logger.BodyWarning("%s and %s: %d classes in %s", s.Dependencies[0], s.Dependencies[1], s.Classes,
+				strings.Join(s.Packages, ", "))
+		}
+	}
+}
+
+// newConflicts finds the conflicts within a collection of dependencies.  Dependencies with the same name are only
+// reported as duplicates, not as split packages.
+func newConflicts(dependencies JARDependencies) conflicts {
+	c := conflicts{}
+
+	versions := make(map[string][]string)
+	for _, d := range dependencies {
+		versions[d.Name] = append(versions[d.Name], d.Version)
+	}
+
+	for n, v := range versions {
+		if len(v) > 1 {
+			sort.Strings(v)
+			c.Duplicates = append(c.Duplicates, duplicate{Name: n, Versions: v})
+		}
+	}
+	sort.Slice(c.Duplicates, func(i, j int) bool {
+		return c.Duplicates[i].Name < c.Duplicates[j].Name
+	})
+
+	owners := make(map[string][]JARDependency)
+	for _, d := range dependencies {
+		for _, cl := range d.classes {
+			owners[cl] = append(owners[cl], d)
+		}
+	}
+
+	splits := make(map[[2]string]*splitPackage)
+	packages := make(map[[2]string]map[string]bool)
+	for cl, o := range owners {
+		for i := 0; i < len(o); i++ {
+			for j := i + 1; j < len(o); j++ {
+				if o[i].Name == o[j].Name {
+					continue
+				}
+
+				k := [2]string{coordinates(o[i]), coordinates(o[j])}
+				if k[0] > k[1] {
+					k[0], k[1] = k[1], k[0]
+				}
+
+				s, ok := splits[k]
+				if !ok {
+					s = &splitPackage{Dependencies: k}
+					splits[k] = s
+					packages[k] = make(map[string]bool)
+				}
+
+				s.Classes++
+				packages[k][strings.ReplaceAll(path.Dir(cl), "/", ".")] = true
+			}
+		}
+	}
+
+	for k, s := range splits {
+		for p := range packages[k] {
+			s.Packages = append(s.Packages, p)
+		}
+		sort.Strings(s.Packages)
+
+		c.SplitPackages = append(c.SplitPackages, *s)
+	}
+	sort.Slice(c.SplitPackages, func(i, j int) bool {
+		a, b := c.SplitPackages[i].Dependencies, c.SplitPackages[j].Dependencies
+		return a[0] < b[0] || (a[0] == b[0] && a[1] < b[1])
+	})
+
+	return c
+}
+
+func coordinates(dependency JARDependency) string {
+	return fmt.Sprintf("%s-%s", dependency.Name, dependency.Version)
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/layers"
+)
+
+// DebugOpts is the launch environment variable containing the JVM options that enable a JDWP agent.  It is only
+// referenced by the debug process type.
+const DebugOpts = "JAVA_DEBUG_OPTS"
+
+// Debug is the configuration of JDWP debugging.
+type Debug struct {
+	// Enabled indicates whether the debug process type is contributed.
+	Enabled bool `toml:"debug-enabled"`
+
+	// Port is the port, optionally prefixed with a host, that the JDWP agent listens on.
+	Port string `toml:"debug-port"`
+
+	// Suspend indicates whether the JVM suspends until a debugger is attached.
+	Suspend bool `toml:"debug-suspend"`
+}
+
+// Contribute writes the JDWP agent configuration as a default in the launch environment.
+func (d Debug) Contribute(layer layers.Layer) error {
+	if !d.Enabled {
+		return nil
+	}
+
+	return layer.DefaultLaunchEnv(DebugOpts, "%s", d.JavaOpts())
+}
+
+// JavaOpts returns the JVM options that enable a JDWP agent.
+func (d Debug) JavaOpts() string {
+	address := d.Port
+	if !strings.Contains(address, ":") {
+		address = fmt.Sprintf("*:%s", address)
+	}
+
+	suspend := "n"
+	if d.Suspend {
+		suspend = "y"
+	}
+
+	return fmt.Sprintf("-agentlib:jdwp=transport=dt_socket,server=y,address=%s,suspend=%s", address, suspend)
+}
+
+// NewDebug creates a new Debug from the $BP_DEBUG_ENABLED, $BP_DEBUG_PORT, and $BP_DEBUG_SUSPEND environment
+// variables.
+func NewDebug() (Debug, error) {
+	d := Debug{Port: "8000"}
+
+	var err error
+	if d.Enabled, err = boolEnv("BP_DEBUG_ENABLED"); err != nil {
+		return Debug{}, err
+	}
+
+	if s, ok := os.LookupEnv("BP_DEBUG_PORT"); ok {
+		p := s[strings.LastIndex(s, ":")+1:]
+		if n, err := strconv.Atoi(p); err != nil || n < 1 || n > 65535 {
+			return Debug{}, fmt.Errorf("invalid $BP_DEBUG_PORT: %s", s)
+		}
+
+		d.Port = s
+	}
+
+	if d.Suspend, err = boolEnv("BP_DEBUG_SUSPEND"); err != nil {
+		return Debug{}, err
+	}
+
+	return d, nil
+}
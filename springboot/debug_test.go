@@ -0,0 +1,70 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot_test
+
+import (
+	"testing"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/test"
+	"github.com/cloudfoundry/spring-boot-cnb/springboot"
+	"github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestDebug(t *testing.T) {
+	spec.Run(t, "Debug", func(t *testing.T, when spec.G, it spec.S) {
+
+		g := gomega.NewWithT(t)
+
+		when("NewDebug", func() {
+
+			it("is disabled by default", func() {
+				g.Expect(springboot.NewDebug()).To(gomega.Equal(springboot.Debug{Port: "8000"}))
+			})
+
+			it("reads configuration", func() {
+				defer test.ReplaceEnv(t, "BP_DEBUG_ENABLED", "true")()
+				defer test.ReplaceEnv(t, "BP_DEBUG_PORT", "127.0.0.1:8001")()
+				defer test.ReplaceEnv(t, "BP_DEBUG_SUSPEND", "true")()
+
+				g.Expect(springboot.NewDebug()).To(gomega.Equal(springboot.Debug{Enabled: true, Port: "127.0.0.1:8001", Suspend: true}))
+			})
+
+			it("rejects invalid enabled", func() {
+				defer test.ReplaceEnv(t, "BP_DEBUG_ENABLED", "alpha")()
+
+				_, err := springboot.NewDebug()
+				g.Expect(err).To(gomega.MatchError("invalid $BP_DEBUG_ENABLED: alpha"))
+			})
+
+			it("rejects invalid port", func() {
+				defer test.ReplaceEnv(t, "BP_DEBUG_PORT", "70000")()
+
+				_, err := springboot.NewDebug()
+				g.Expect(err).To(gomega.MatchError("invalid $BP_DEBUG_PORT: 70000"))
+			})
+		})
+
+		it("returns JAVA_OPTS", func() {
+			g.Expect(springboot.Debug{Enabled: true, Port: "8000"}.JavaOpts()).
+				To(gomega.Equal("-agentlib:jdwp=transport=dt_socket,server=y,address=*:8000,suspend=n"))
+			g.Expect(springboot.Debug{Enabled: true, Port: "127.0.0.1:8000", Suspend: true}.JavaOpts()).
+				To(gomega.Equal("-agentlib:jdwp=transport=dt_socket,server=y,address=127.0.0.1:8000,suspend=y"))
+		})
+	}, spec.Report(report.Terminal{}))
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/helper"
+	"github.com/cloudfoundry/libcfbuildpack/v2/layers"
+)
+
+// DevToolsOpts is the launch environment variable containing the JVM options that configure the DevTools remote
+// secret.  It is only referenced by the dev process type, so other process types never expose the remote endpoint.
+const DevToolsOpts = "JAVA_DEVTOOLS_OPTS"
+
+// devToolsStatic are the paths, relative to Spring-Boot-Classes, that Spring Boot DevTools reloads without restarting.
+var devToolsStatic = []string{"META-INF/resources", "public", "resources", "static", "templates"}
+
+// DevTools is the configuration of the Spring Boot DevTools development mode.
+type DevTools struct {
+	// Enabled indicates whether development mode is enabled.
+	Enabled bool
+
+	// RemoteSecret is the shared secret used to connect a remote DevTools client.
+	RemoteSecret string
+}
+
+// Contribute writes the remote secret option as a default in the launch environment, keeping the secret out of process
+// commands.
+func (d DevTools) Contribute(layer layers.Layer) error {
+	if !d.Enabled || d.RemoteSecret == "" {
+		return nil
+	}
+
+	return layer.DefaultLaunchEnv(DevToolsOpts, "-Dspring.devtools.remote.secret=%s", d.RemoteSecret)
+}
+
+// JavaOpts returns the JVM options of the dev process type that enable DevTools restart and, if there is a remote
+// secret, reference it in the launch environment.
+func (d DevTools) JavaOpts() string {
+	if d.RemoteSecret == "" {
+		return "-Dspring.devtools.restart.enabled=true"
+	}
+
+	return "-Dspring.devtools.restart.enabled=true $" + DevToolsOpts
+}
+
+// remoteSecretHash returns the SHA256 hash of the remote secret, identifying it in layer metadata without exposing
+// it.  Empty if there is no remote secret.
+func (d DevTools) remoteSecretHash() string {
+	if !d.Enabled || d.RemoteSecret == "" {
+		return ""
+	}
+
+	h := sha256.Sum256([]byte(d.RemoteSecret))
+	return hex.EncodeToString(h[:])
+}
+
+// Sync returns the paths, relative to the application root, that can be synced into a running container.  Changes to
+// restart paths trigger an application restart and changes to reload paths trigger a LiveReload.
+func (d DevTools) Sync(root string, classes string) (map[string]interface{}, error) {
+	var reload []string
+
+	for _, s := range devToolsStatic {
+		p := filepath.Join(classes, s)
+
+		if exists, err := helper.FileExists(filepath.Join(root, p)); err != nil {
+			return nil, err
+		} else if exists {
+			reload = append(reload, p)
+		}
+	}
+
+	return map[string]interface{}{
+		"destination": root,
+		"reload":      reload,
+		"restart":     []string{classes},
+	}, nil
+}
+
+// NewDevTools creates a new DevTools from the $BP_SPRING_BOOT_DEVELOPMENT_MODE and
+// $BP_SPRING_BOOT_DEVTOOLS_REMOTE_SECRET environment variables.
+func NewDevTools() (DevTools, error) {
+	d := DevTools{}
+
+	var err error
+	if d.Enabled, err = boolEnv("BP_SPRING_BOOT_DEVELOPMENT_MODE"); err != nil {
+		return DevTools{}, err
+	}
+
+	if s, ok := os.LookupEnv("BP_SPRING_BOOT_DEVTOOLS_REMOTE_SECRET"); ok {
+		if strings.ContainsAny(s, " \t\n'\"$`\\") {
+			return DevTools{}, fmt.Errorf(
+				"invalid $BP_SPRING_BOOT_DEVTOOLS_REMOTE_SECRET, must not contain whitespace, quotes, '$', '`', or '\\'")
+		}
+
+		d.RemoteSecret = s
+	}
+
+	return d, nil
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/test"
+	"github.com/cloudfoundry/spring-boot-cnb/springboot"
+	"github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestDevTools(t *testing.T) {
+	spec.Run(t, "DevTools", func(t *testing.T, when spec.G, it spec.S) {
+
+		g := gomega.NewWithT(t)
+
+		when("NewDevTools", func() {
+
+			it("is disabled by default", func() {
+				g.Expect(springboot.NewDevTools()).To(gomega.Equal(springboot.DevTools{}))
+			})
+
+			it("reads configuration", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_DEVELOPMENT_MODE", "true")()
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_DEVTOOLS_REMOTE_SECRET", "test-secret")()
+
+				g.Expect(springboot.NewDevTools()).To(gomega.Equal(springboot.DevTools{Enabled: true, RemoteSecret: "test-secret"}))
+			})
+
+			it("rejects invalid development mode", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_DEVELOPMENT_MODE", "alpha")()
+
+				_, err := springboot.NewDevTools()
+				g.Expect(err).To(gomega.MatchError("invalid $BP_SPRING_BOOT_DEVELOPMENT_MODE: alpha"))
+			})
+
+			it("rejects invalid remote secret", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_DEVTOOLS_REMOTE_SECRET", "test secret")()
+
+				_, err := springboot.NewDevTools()
+				g.Expect(err).To(gomega.HaveOccurred())
+			})
+		})
+
+		it("returns JAVA_OPTS", func() {
+			g.Expect(springboot.DevTools{Enabled: true}.JavaOpts()).
+				To(gomega.Equal("-Dspring.devtools.restart.enabled=true"))
+			g.Expect(springboot.DevTools{Enabled: true, RemoteSecret: "test-secret"}.JavaOpts()).
+				To(gomega.Equal("-Dspring.devtools.restart.enabled=true $JAVA_DEVTOOLS_OPTS"))
+		})
+
+		it("returns sync paths", func() {
+			root := test.ScratchDir(t, "devtools")
+			test.TouchFile(t, root, "test-classes", "static", "index.html")
+			test.TouchFile(t, root, "test-classes", "templates", "index.html")
+
+			g.Expect(springboot.DevTools{Enabled: true}.Sync(root, "test-classes")).To(gomega.Equal(map[string]interface{}{
+				"destination": root,
+				"reload": []string{
+					filepath.Join("test-classes", "static"),
+					filepath.Join("test-classes", "templates"),
+				},
+				"restart": []string{"test-classes"},
+			}))
+		})
+	}, spec.Report(report.Terminal{}))
+}
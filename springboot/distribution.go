@@ -0,0 +1,102 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/helper"
+)
+
+// newDistribution creates a new packaging from a Spring Boot distribution, created by Gradle's bootDistZip and
+// bootDistTar tasks, containing start scripts in bin/ and the boot jar in lib/.  OK is false if the application is not
+// a distribution.
+func newDistribution(root string) (packaging, bool, error) {
+	if exists, err := helper.FileExists(filepath.Join(root, "bin")); err != nil {
+		return packaging{}, false, err
+	} else if !exists {
+		return packaging{}, false, nil
+	}
+
+	candidates, err := filepath.Glob(filepath.Join(root, "lib", "*.jar"))
+	if err != nil {
+		return packaging{}, false, err
+	}
+
+	p := packaging{Lib: "lib/"}
+	for _, c := range candidates {
+		a, ok, err := newArtifact(root, c)
+		if err != nil {
+			return packaging{}, false, err
+		} else if !ok {
+			continue
+		}
+
+		if len(p.Artifacts) > 0 {
+			return packaging{}, false, fmt.Errorf("found multiple Spring Boot jars in lib/: %s and %s",
+				filepath.Base(p.Artifacts[0].Path), filepath.Base(c))
+		}
+
+		p.Artifacts = append(p.Artifacts, a)
+	}
+
+	if len(p.Artifacts) == 0 {
+		return packaging{}, false, nil
+	}
+
+	if p.Script, err = startScript(root, p.Artifacts[0].Path); err != nil {
+		return packaging{}, false, err
+	}
+
+	return p, true, nil
+}
+
+// startScript returns the start script in bin/ for a boot jar.  Gradle names the start script after the application,
+// so a single script is used directly and otherwise the script whose name prefixes the name of the boot jar.
+func startScript(root string, jar string) (string, error) {
+	c, err := filepath.Glob(filepath.Join(root, "bin", "*"))
+	if err != nil {
+		return "", err
+	}
+
+	var scripts []string
+	for _, s := range c {
+		if i, err := os.Stat(s); err != nil {
+			return "", err
+		} else if i.IsDir() || i.Mode()&0111 == 0 || strings.HasSuffix(s, ".bat") {
+			continue
+		}
+
+		scripts = append(scripts, filepath.Base(s))
+	}
+
+	if len(scripts) == 1 {
+		return filepath.Join("bin", scripts[0]), nil
+	}
+
+	name := filepath.Base(jar)
+	for _, s := range scripts {
+		if strings.HasPrefix(name, s+"-") || name == s+".jar" {
+			return filepath.Join("bin", s), nil
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/logger"
+)
+
+// javaEE matches references to the Java EE javax.* namespaces that were renamed to jakarta.* by Jakarta EE 9.  Java SE
+// namespaces such as javax.annotation.processing and javax.transaction.xa are not matched.
+var javaEE = regexp.MustCompile(`javax/(activation|` +
+	`annotation/(?:security/|sql/|(?:Generated|ManagedBean|PostConstruct|PreDestroy|Priority|Resources?)\b)|` +
+	`batch|decorator|ejb|el|enterprise|faces|inject|interceptor|jms|json|jws|mail|persistence|resource|` +
+	`security/(?:auth/message|enterprise|jacc)|servlet|transaction/[A-Z]|validation|websocket|ws/rs|` +
+	`xml/bind|xml/soap|xml/ws)`)
+
+// JakartaReport is the configuration of reporting dependencies that use Java EE javax.* namespaces.
+type JakartaReport struct {
+	// Enabled indicates whether dependencies are scanned for and reported using Java EE javax.* namespaces.
+	Enabled bool
+}
+
+// NewJakartaReport creates a new JakartaReport for a Spring Boot version.  The report is always enabled for Spring
+// Boot 3 and later, and otherwise from the $BP_SPRING_BOOT_JAKARTA_REPORT environment variable.
+func NewJakartaReport(version string) (JakartaReport, error) {
+	if l, err := parseReleaseLine(releaseLine(version)); err == nil && l[0] >= 3 {
+		return JakartaReport{Enabled: true}, nil
+	}
+
+	e, err := boolEnv("BP_SPRING_BOOT_JAKARTA_REPORT")
+	if err != nil {
+		return JakartaReport{}, err
+	}
+
+	return JakartaReport{Enabled: e}, nil
+}
+
+// eeNamespaces returns the Java EE javax.* namespaces referenced by a string.
+func eeNamespaces(s string) []string {
+	var n []string
+
+	for _, m := range javaEE.FindAllStringSubmatch(s, -1) {
+		var p []string
+		for _, e := range strings.Split(m[1], "/") {
+			if e == "" || unicode.IsUpper(rune(e[0])) {
+				break
+			}
+			p = append(p, e)
+		}
+
+		n = append(n, "javax."+strings.Join(p, "."))
+	}
+
+	return n
+}
+
+// logJakarta reports dependencies that use Java EE javax.* namespaces.
+func logJakarta(dependencies JARDependencies, version string, logger logger.Logger) {
+	var d JARDependencies
+	for _, j := range dependencies {
+		if len(j.EENamespaces) > 0 {
+			d = append(d, j)
+		}
+	}
+
+	if len(d) == 0 {
+		return
+	}
+
+	if l, err := parseReleaseLine(releaseLine(version)); err == nil && l[0] >= 3 {
+		logger.HeaderWarning("Found dependencies using Java EE javax.* namespaces that are not supported by Spring Boot %s",
+			version)
+	} else {
+		logger.HeaderWarning("Found dependencies using Java EE javax.* namespaces that block a migration to Jakarta EE")
+	}
+
+	for _, j := range d {
+		logger.BodyWarning("%s %s: %s", j.Name, j.Version, strings.Join(j.EENamespaces, ", "))
+	}
+}
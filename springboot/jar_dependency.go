@@ -17,27 +17,124 @@
 package springboot
 
 import (
+	"archive/zip"
+	"bufio"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/cloudfoundry/libcfbuildpack/v2/logger"
 )
 
-var pattern = regexp.MustCompile(".+/(.*)-([\\d].*)\\.jar")
+const (
+	// DevelopmentScope indicates that a dependency is only used during development.
+	DevelopmentScope = "development"
+
+	// TestScope indicates that a dependency is only used during testing.
+	TestScope = "test"
+)
+
+var (
+	pattern = regexp.MustCompile(".+/(.*)-([\\d].*)\\.jar")
+
+	scopes = []struct {
+		name  *regexp.Regexp
+		scope string
+	}{
+		{regexp.MustCompile(`^spring-boot-devtools$`), DevelopmentScope},
+		{regexp.MustCompile(`^(assertj-core|hamcrest.*|jsonassert|junit|junit-jupiter.*|junit-platform-.*|` +
+			`junit-vintage-engine)$`), TestScope},
+		{regexp.MustCompile(`^(mockito-.*|spring-boot-starter-test|spring-boot-test|spring-boot-test-autoconfigure|` +
+			`spring-test|testcontainers)$`), TestScope},
+	}
+)
+
+// DevelopmentDependencies is the configuration of development and test dependencies on the launch classpath.
+type DevelopmentDependencies struct {
+	// Keep indicates whether development and test dependencies are kept on the classpath.
+	Keep bool
+}
+
+// NewDevelopmentDependencies creates a new DevelopmentDependencies from the
+// $BP_SPRING_BOOT_KEEP_DEVELOPMENT_DEPENDENCIES environment variable.
+func NewDevelopmentDependencies() (DevelopmentDependencies, error) {
+	k, err := boolEnv("BP_SPRING_BOOT_KEEP_DEVELOPMENT_DEPENDENCIES")
+	if err != nil {
+		return DevelopmentDependencies{}, err
+	}
+
+	return DevelopmentDependencies{Keep: k}, nil
+}
+
+// excludedScopes returns the scopes of the dependencies removed from the classpath.  Development dependencies are
+// kept in development mode.
+func (d DevelopmentDependencies) excludedScopes(devTools DevTools) []string {
+	if d.Keep {
+		return nil
+	} else if devTools.Enabled {
+		return []string{TestScope}
+	}
+
+	return []string{DevelopmentScope, TestScope}
+}
 
 // JARDependency represents a JAR dependency within an application
 type JARDependency struct {
 	Name    string `toml:"name"`
 	Version string `toml:"version"`
 	SHA256  string `toml:"sha256"`
+
+	// Scope indicates that a dependency is only used during development or testing.  Empty for runtime dependencies.
+	Scope string `toml:"scope,omitempty"`
+
+	// Agent indicates that a dependency is a Java agent that can be attached with -javaagent, declaring a
+	// Premain-Class.  A Launcher-Agent-Class is only started by the executable JAR that declares it.
+	Agent bool `toml:"agent,omitempty"`
+
+	// LauncherAgent indicates that a dependency declares a Launcher-Agent-Class but no Premain-Class.
+	LauncherAgent bool `toml:"launcher-agent,omitempty"`
+
+	// EENamespaces are the Java EE javax.* namespaces contained in or referenced by a dependency.  Only scanned for
+	// Spring Boot 3 applications or when a Jakarta EE migration report is requested.
+	EENamespaces []string `toml:"ee-namespaces,omitempty"`
+
+	// Module is the name of a dependency's module, declared by a module-info.class or the Automatic-Module-Name
+	// manifest key.  Empty if the dependency is not a named or automatic module.
+	Module string `toml:"module,omitempty"`
+
+	classes     []string
+	nativeImage []nativeImageFile
+}
+
+func (d JARDependency) matches(path string) bool {
+	m := pattern.FindStringSubmatch(path)
+	return m != nil && m[1] == d.Name && m[2] == d.Version
 }
 
 // NewJARDependency creates a new instance of JAR dependency, returning true if it matches the standard Maven naming
 // scheme.
 func NewJARDependency(path string, logger logger.Logger) (JARDependency, bool, error) {
+	return newJARDependency(path, scan{}, logger)
+}
+
+// scan indicates the optional, more expensive, parts of scanning a JAR.
+type scan struct {
+	// nativeImage indicates whether META-INF/native-image JSON configuration is read.
+	nativeImage bool
+
+	// references indicates whether class references are scanned for Java EE javax.* namespaces.
+	references bool
+}
+
+// newJARDependency creates a new instance of JAR dependency, scanning its contents as requested.
+func newJARDependency(path string, scan scan, logger logger.Logger) (JARDependency, bool, error) {
 	m := pattern.FindStringSubmatch(path)
 	if m == nil {
 		return JARDependency{}, false, nil
@@ -48,13 +145,217 @@ func NewJARDependency(path string, logger logger.Logger) (JARDependency, bool, e
 		return JARDependency{}, false, err
 	}
 
+	j, err := readJAR(path, scan, logger)
+	if err != nil {
+		logger.Debug("Unable to read contents of %s: %s", path, err)
+	}
+
 	return JARDependency{
-		Name:    m[1],
-		Version: m[2],
-		SHA256:  h,
+		Name:          m[1],
+		Version:       m[2],
+		SHA256:        h,
+		Scope:         scope(m[1]),
+		Agent:         j.manifest["Premain-Class"] != "",
+		LauncherAgent: j.manifest["Premain-Class"] == "" && j.manifest["Launcher-Agent-Class"] != "",
+		EENamespaces:  j.eeNamespaces,
+		Module:        j.module,
+		classes:       j.classes,
+		nativeImage:   j.nativeImage,
 	}, true, nil
 }
 
+// jar is the contents of a JAR relevant to inspecting it as a dependency.
+type jar struct {
+	// classes are the names of the class entries, excluding module-info.class and multi-release versions.
+	classes []string
+
+	// eeNamespaces are the Java EE javax.* namespaces contained in or referenced by the classes.
+	eeNamespaces []string
+
+	// manifest are the main attributes of META-INF/MANIFEST.MF.
+	manifest map[string]string
+
+	// module is the name of the module declared by module-info.class or the Automatic-Module-Name manifest key.
+	module string
+
+	// nativeImage are the META-INF/native-image JSON configuration files.
+	nativeImage []nativeImageFile
+}
+
+// readJAR reads the contents of a JAR, skipping entries that cannot be read so that the rest of the JAR is still
+// inspected.
+func readJAR(path string, scan scan, logger logger.Logger) (jar, error) {
+	z, err := zip.OpenReader(path)
+	if err != nil {
+		return jar{}, err
+	}
+	defer z.Close()
+
+	j := jar{manifest: map[string]string{}}
+	ee := make(map[string]bool)
+
+	for _, f := range z.File {
+		if f.Name == "META-INF/MANIFEST.MF" {
+			m, err := readManifest(f)
+			if err != nil {
+				logger.Debug("Unable to read %s!/%s: %s", path, f.Name, err)
+				continue
+			}
+
+			j.manifest = m
+			continue
+		}
+
+		if scan.nativeImage && isNativeImageFile(f.Name) {
+			b, err := readFile(f)
+			if err != nil {
+				logger.Debug("Unable to read %s!/%s: %s", path, f.Name, err)
+				continue
+			}
+
+			j.nativeImage = append(j.nativeImage, nativeImageFile{
+				Source:  fmt.Sprintf("%s!/%s", filepath.Base(path), f.Name),
+				Name:    f.Name[strings.LastIndex(f.Name, "/")+1:],
+				Content: b,
+			})
+			continue
+		}
+
+		if isModuleInfo(f.Name) {
+			n, err := readModuleName(f)
+			if err != nil {
+				logger.Debug("Unable to read %s!/%s: %s", path, f.Name, err)
+				continue
+			}
+
+			j.module = n
+			continue
+		}
+
+		if !strings.HasSuffix(f.Name, ".class") {
+			continue
+		}
+
+		if !strings.HasPrefix(f.Name, "META-INF/") && f.Name != "module-info.class" {
+			j.classes = append(j.classes, f.Name)
+		}
+
+		if !scan.references {
+			continue
+		}
+
+		for _, n := range eeNamespaces(f.Name) {
+			ee[n] = true
+		}
+
+		c, err := readConstantStrings(f)
+		if err != nil {
+			logger.Debug("Unable to read %s!/%s: %s", path, f.Name, err)
+			continue
+		}
+
+		for _, s := range c {
+			for _, n := range eeNamespaces(s) {
+				ee[n] = true
+			}
+		}
+	}
+
+	if j.module == "" {
+		j.module = j.manifest["Automatic-Module-Name"]
+	}
+
+	for n := range ee {
+		j.eeNamespaces = append(j.eeNamespaces, n)
+	}
+	sort.Strings(j.eeNamespaces)
+
+	return j, nil
+}
+
+// isModuleInfo returns whether an entry is a module descriptor, at the root of a JAR or in a multi-release version.
+func isModuleInfo(name string) bool {
+	return name == "module-info.class" ||
+		(strings.HasPrefix(name, "META-INF/versions/") && strings.HasSuffix(name, "/module-info.class"))
+}
+
+func readModuleName(f *zip.File) (string, error) {
+	r, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	return moduleName(r)
+}
+
+func readConstantStrings(f *zip.File) ([]string, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return constantStrings(r)
+}
+
+func readFile(f *zip.File) ([]byte, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ioutil.ReadAll(r)
+}
+
+func readManifest(f *zip.File) (map[string]string, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return parseManifest(r)
+}
+
+// parseManifest parses the main section of a manifest, joining continuation lines.
+func parseManifest(r io.Reader) (map[string]string, error) {
+	m := make(map[string]string)
+	var last string
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		l := strings.TrimRight(s.Text(), "\r")
+
+		if l == "" {
+			break
+		}
+
+		if strings.HasPrefix(l, " ") {
+			m[last] += l[1:]
+			continue
+		}
+
+		if kv := strings.SplitN(l, ":", 2); len(kv) == 2 {
+			last = strings.TrimSpace(kv[0])
+			m[last] = strings.TrimSpace(kv[1])
+		}
+	}
+
+	return m, s.Err()
+}
+
+func scope(name string) string {
+	for _, s := range scopes {
+		if s.name.MatchString(name) {
+			return s.scope
+		}
+	}
+
+	return ""
+}
+
 func hash(file string) (string, error) {
 	s := sha256.New()
 
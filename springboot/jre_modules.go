@@ -0,0 +1,335 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/buildpacks/libbuildpack/v2/application"
+	"github.com/cloudfoundry/libcfbuildpack/v2/helper"
+	"github.com/cloudfoundry/libcfbuildpack/v2/layers"
+	"github.com/cloudfoundry/libcfbuildpack/v2/logger"
+)
+
+// JREModulesDependency is the name of the layer containing the list of JDK modules used by an application.
+const JREModulesDependency = "jre-modules"
+
+var (
+	// descriptorClass matches the classes referenced by field, method, and generic signature descriptors.
+	descriptorClass = regexp.MustCompile(`L([\w$/]+)[;<]`)
+
+	// internalClass matches a class name in internal form.
+	internalClass = regexp.MustCompile(`^[\w$]+(/[\w$]+)+$`)
+
+	// serviceProviders are the JDK modules that are only loaded as service providers, and so are never referenced by
+	// an application's classes, but are required by most applications.  jdk.crypto.ec provides the elliptic curves
+	// used by TLS.
+	serviceProviders = []string{"jdk.crypto.ec"}
+
+	// jdkPackages maps package prefixes, in internal form, to the JDK modules that export them.  The longest matching
+	// prefix wins and an empty module marks packages that are no longer part of the JDK.
+	jdkPackages = map[string]string{
+		"com/sun/jdi/":                  "jdk.jdi",
+		"com/sun/management/":           "jdk.management",
+		"com/sun/net/httpserver/":       "jdk.httpserver",
+		"com/sun/nio/sctp/":             "jdk.sctp",
+		"com/sun/security/auth/":        "jdk.security.auth",
+		"com/sun/security/jgss/":        "jdk.security.jgss",
+		"com/sun/source/":               "jdk.compiler",
+		"com/sun/tools/attach/":         "jdk.attach",
+		"java/applet/":                  "java.desktop",
+		"java/awt/":                     "java.desktop",
+		"java/beans/":                   "java.desktop",
+		"java/io/":                      "java.base",
+		"java/lang/":                    "java.base",
+		"java/lang/instrument/":         "java.instrument",
+		"java/lang/management/":         "java.management",
+		"java/math/":                    "java.base",
+		"java/net/":                     "java.base",
+		"java/net/http/":                "java.net.http",
+		"java/nio/":                     "java.base",
+		"java/rmi/":                     "java.rmi",
+		"java/security/":                "java.base",
+		"java/sql/":                     "java.sql",
+		"java/text/":                    "java.base",
+		"java/time/":                    "java.base",
+		"java/util/":                    "java.base",
+		"java/util/logging/":            "java.logging",
+		"java/util/prefs/":              "java.prefs",
+		"javax/accessibility/":          "java.desktop",
+		"javax/annotation/processing/":  "java.compiler",
+		"javax/crypto/":                 "java.base",
+		"javax/imageio/":                "java.desktop",
+		"javax/lang/model/":             "java.compiler",
+		"javax/management/":             "java.management",
+		"javax/management/remote/rmi/":  "java.management.rmi",
+		"javax/naming/":                 "java.naming",
+		"javax/net/":                    "java.base",
+		"javax/print/":                  "java.desktop",
+		"javax/rmi/ssl/":                "java.rmi",
+		"javax/script/":                 "java.scripting",
+		"javax/security/auth/":          "java.base",
+		"javax/security/auth/kerberos/": "java.security.jgss",
+		"javax/security/cert/":          "java.base",
+		"javax/security/sasl/":          "java.security.sasl",
+		"javax/smartcardio/":            "java.smartcardio",
+		"javax/sound/":                  "java.desktop",
+		"javax/sql/":                    "java.sql",
+		"javax/sql/rowset/":             "java.sql.rowset",
+		"javax/swing/":                  "java.desktop",
+		"javax/tools/":                  "java.compiler",
+		"javax/transaction/xa/":         "java.transaction.xa",
+		"javax/xml/":                    "java.xml",
+		"javax/xml/bind/":               "",
+		"javax/xml/crypto/":             "java.xml.crypto",
+		"javax/xml/soap/":               "",
+		"javax/xml/ws/":                 "",
+		"jdk/dynalink/":                 "jdk.dynalink",
+		"jdk/jfr/":                      "jdk.jfr",
+		"jdk/net/":                      "jdk.net",
+		"netscape/javascript/":          "jdk.jsobject",
+		"org/ietf/jgss/":                "java.security.jgss",
+		"org/w3c/dom/":                  "java.xml",
+		"org/xml/sax/":                  "java.xml",
+		"sun/misc/":                     "jdk.unsupported",
+		"sun/reflect/":                  "jdk.unsupported",
+	}
+)
+
+// jreModules is the list of JDK modules used by an application.
+type jreModules struct {
+	Modules []string `toml:"modules"`
+}
+
+func (j jreModules) Identity() (string, string) {
+	return "JRE Modules", ""
+}
+
+// Contribute writes the list of modules, one per line, to modules.txt in a layer marked launch.
+func (j jreModules) Contribute(layer layers.Layer) error {
+	return layer.Contribute(j, func(layer layers.Layer) error {
+		if err := os.RemoveAll(layer.Root); err != nil {
+			return err
+		}
+
+		layer.Logger.Body("Writing %s", strings.Join(j.Modules, ","))
+		return helper.WriteFile(filepath.Join(layer.Root, "modules.txt"), 0644, "%s\n", strings.Join(j.Modules, "\n"))
+	}, layers.Launch)
+}
+
+// jreModulesEnabled returns whether JDK module analysis is requested with $BP_SPRING_BOOT_JRE_MODULES.
+func jreModulesEnabled() (bool, error) {
+	return boolEnv("BP_SPRING_BOOT_JRE_MODULES")
+}
+
+// NewJREModules returns the JDK modules used by the classes of a Spring Boot application and its runtime dependencies,
+// scanning the same classpath as the build.  OK is false if $BP_SPRING_BOOT_JRE_MODULES is not true, the application is
+// not a Spring Boot application, or the dependencies of a thin launcher application cannot be resolved yet, in which
+// case the full JRE is required.
+func NewJREModules(application application.Application, platform string, logger logger.Logger) ([]string, bool, error) {
+	if e, err := jreModulesEnabled(); err != nil || !e {
+		return nil, false, err
+	}
+
+	md, ok, err := NewMetadata(application, logger)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	d, err := NewDevTools()
+	if err != nil {
+		return nil, false, err
+	}
+
+	dd, err := NewDevelopmentDependencies()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var t thin
+	if md.Lib == "" {
+		r, err := thinRepository(platform)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if t, _, err = newThin(application.Root, r); err != nil {
+			logger.Debug("Requiring full JRE: %s", err)
+			return nil, false, nil
+		}
+	}
+
+	m, err := newJREModules(jreModulesClassPath(md.ClassPath, t, dd.excludedScopes(d)), logger)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return m, true, nil
+}
+
+// jreModulesClassPath returns the classpath scanned for JDK modules: the application's classpath without the
+// dependencies in the excluded scopes, followed by the thin launcher dependencies in the repository they are resolved
+// from, as they are not copied until contribution.
+func jreModulesClassPath(classPath []string, t thin, excluded []string) []string {
+	var cp []string
+	for _, c := range classPath {
+		if m := pattern.FindStringSubmatch(c); m != nil && contains(excluded, scope(m[1])) {
+			continue
+		}
+		cp = append(cp, c)
+	}
+
+	for _, a := range t.Artifacts {
+		cp = append(cp, a.source)
+	}
+
+	return cp
+}
+
+// newJREModules returns the JDK modules referenced by the classes in the directories and JARs, including nested JARs,
+// of a classpath.  java.base, the known service providers, and the modules in $BP_SPRING_BOOT_JRE_MODULES_EXTRA are
+// always included.
+func newJREModules(classPath []string, logger logger.Logger) ([]string, error) {
+	modules := map[string]bool{"java.base": true}
+	for _, m := range serviceProviders {
+		modules[m] = true
+	}
+	for _, m := range strings.FieldsFunc(os.Getenv("BP_SPRING_BOOT_JRE_MODULES_EXTRA"), func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	}) {
+		modules[m] = true
+	}
+
+	for _, c := range classPath {
+		i, err := os.Stat(c)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if i.IsDir() {
+			err = filepath.Walk(c, func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() || filepath.Ext(path) != ".class" {
+					return err
+				}
+
+				f, err := os.Open(path)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+
+				return addJDKModules(f, modules)
+			})
+		} else if filepath.Ext(c) == ".jar" || filepath.Ext(c) == ".war" {
+			var b []byte
+			if b, err = ioutil.ReadFile(c); err == nil {
+				err = addArchiveJDKModules(b, modules)
+			}
+		}
+
+		if err != nil {
+			logger.Debug("Unable to read classes of %s: %s", c, err)
+		}
+	}
+
+	var m []string
+	for k := range modules {
+		m = append(m, k)
+	}
+	sort.Strings(m)
+
+	return m, nil
+}
+
+func addArchiveJDKModules(b []byte, modules map[string]bool) error {
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return err
+	}
+
+	for _, f := range z.File {
+		if !strings.HasSuffix(f.Name, ".class") && !strings.HasSuffix(f.Name, ".jar") {
+			continue
+		}
+
+		c, err := readFile(f)
+		if err != nil {
+			return err
+		}
+
+		if strings.HasSuffix(f.Name, ".jar") {
+			err = addArchiveJDKModules(c, modules)
+		} else if !isModuleInfo(f.Name) {
+			err = addJDKModules(bytes.NewReader(c), modules)
+		}
+
+		if err != nil {
+			return fmt.Errorf("unable to read %s: %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func addJDKModules(r io.Reader, modules map[string]bool) error {
+	s, err := constantStrings(r)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range s {
+		if internalClass.MatchString(c) {
+			if m := jdkModule(c); m != "" {
+				modules[m] = true
+			}
+			continue
+		}
+
+		for _, d := range descriptorClass.FindAllStringSubmatch(c, -1) {
+			if m := jdkModule(d[1]); m != "" {
+				modules[m] = true
+			}
+		}
+	}
+
+	return nil
+}
+
+// jdkModule returns the JDK module exporting a class, in internal form.  Empty if the class is not part of the JDK.
+func jdkModule(class string) string {
+	m, l := "", 0
+
+	for p, module := range jdkPackages {
+		if len(p) > l && strings.HasPrefix(class, p) {
+			m, l = module, len(p)
+		}
+	}
+
+	return m
+}
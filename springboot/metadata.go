@@ -17,17 +17,28 @@
 package springboot
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 
 	"github.com/buildpacks/libbuildpack/v2/application"
 	"github.com/cloudfoundry/libcfbuildpack/v2/helper"
 	"github.com/cloudfoundry/libcfbuildpack/v2/logger"
 	"github.com/cloudfoundry/libcfbuildpack/v2/manifest"
+	"github.com/magiconair/properties"
 )
 
 // Metadata describes the application's metadata.
 type Metadata struct {
+	// AdditionalClassPath are the loader.path and Class-Path manifest entries of a Spring Boot application, relative
+	// to the application root.
+	AdditionalClassPath []string `mapstructure:"additional-classpath" properties:",default=" toml:"additional-classpath"`
+
+	// ArtifactID is the artifact ID of a Spring Boot application.
+	ArtifactID string `mapstructure:"artifact-id" properties:"Implementation-Title,default=" toml:"artifact-id"`
+
 	// Classes indicates the Spring-Boot-Classes of a Spring Boot application.
 	Classes string `mapstructure:"classes" properties:"Spring-Boot-Classes,default=" toml:"classes"`
 
@@ -42,35 +53,225 @@ type Metadata struct {
 
 	// Version indicates the Spring-Boot-Version of a Spring Boot application.
 	Version string `mapstructure:"version" properties:"Spring-Boot-Version,default=" toml:"version"`
+
+	// WebApplicationType is the type of web application, servlet, reactive, or none, of a Spring Boot application.
+	WebApplicationType string `mapstructure:"web-application-type" properties:",default=" toml:"web-application-type"`
+
+	// WebServer is the embedded web server, tomcat, jetty, undertow, reactor-netty, or none, of a Spring Boot
+	// application.
+	WebServer string `mapstructure:"web-server" properties:",default=" toml:"web-server"`
 }
 
 func (m Metadata) Identity() (string, string) {
 	return "Spring Boot", m.Version
 }
 
-// NewMetadata creates a new Metadata returning false if Spring-Boot-Version is not defined.
+// isAdditional returns whether a path, relative to the application root, is or is in an additional classpath entry.
+func (m Metadata) isAdditional(path string) bool {
+	for _, a := range m.AdditionalClassPath {
+		if path == a || strings.HasPrefix(path, strings.TrimSuffix(a, "/")+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewMetadata creates a new Metadata returning false if Spring-Boot-Version is not defined.  If the application is a
+// Spring Boot distribution or contains Spring Boot artifacts in its root, the metadata is read from the manifest of its
+// first artifact.
 func NewMetadata(application application.Application, logger logger.Logger) (Metadata, bool, error) {
+	md, _, ok, err := newMetadata(application, logger)
+	return md, ok, err
+}
+
+func newMetadata(application application.Application, logger logger.Logger) (Metadata, packaging, bool, error) {
 	md := Metadata{}
 
 	m, err := manifest.NewManifest(application, logger)
 	if err != nil {
-		return Metadata{}, false, err
+		return Metadata{}, packaging{}, false, err
 	}
 
 	if err := m.Decode(&md); err != nil {
-		return Metadata{}, false, err
+		return Metadata{}, packaging{}, false, err
+	}
+
+	r, err := findRootArtifacts(application.Root, "")
+	if err != nil {
+		return Metadata{}, packaging{}, false, err
 	}
 
 	if md.Version == "" {
-		return Metadata{}, false, nil
+		p, ok, err := newDistribution(application.Root)
+		if err != nil {
+			return Metadata{}, packaging{}, false, err
+		} else if ok && len(r) > 0 {
+			return Metadata{}, packaging{}, false, fmt.Errorf(
+				"found both a distribution with %s and Spring Boot artifacts in the application root: %s",
+				p.Artifacts[0].Path, artifactPaths(r))
+		} else if !ok {
+			if p, ok, err = newRootArtifacts(application.Root); err != nil || !ok {
+				return Metadata{}, packaging{}, false, err
+			}
+		}
+
+		if md, err = newPackagedMetadata(application.Root, p); err != nil {
+			return Metadata{}, packaging{}, false, err
+		}
+
+		return md, p, true, nil
+	}
+
+	if len(r) > 0 {
+		return Metadata{}, packaging{}, false, fmt.Errorf(
+			"found both an exploded Spring Boot application and Spring Boot artifacts in the application root: %s",
+			artifactPaths(r))
+	}
+
+	if md.Classes == "" && md.Lib == "" {
+		if md.Classes, md.Lib, err = layout(application.Root, md.Version); err != nil {
+			return Metadata{}, packaging{}, false, err
+		}
 	}
 
 	j, err := helper.FindFiles(application.Root, regexp.MustCompile(".*\\.jar$"))
 	if err != nil {
-		return Metadata{}, false, err
+		return Metadata{}, packaging{}, false, err
+	}
+
+	if md.ArtifactID, err = artifactID(application.Root, md.ArtifactID); err != nil {
+		return Metadata{}, packaging{}, false, err
+	}
+
+	l, err := loaderPath(application.Root, md.Classes, m.GetString("Main-Class", ""), m.GetString("Loader-Path", ""))
+	if err != nil {
+		return Metadata{}, packaging{}, false, err
+	}
+
+	lRel, lClassPath, err := resolveClassPath(application.Root, l, true, logger)
+	if err != nil {
+		return Metadata{}, packaging{}, false, err
+	}
+
+	mc := manifestClassPath(m.GetString("Class-Path", ""), logger)
+	mRel, mClassPath, err := resolveClassPath(application.Root, mc, false, logger)
+	if err != nil {
+		return Metadata{}, packaging{}, false, err
+	}
+
+	md.AdditionalClassPath = append(lRel, mRel...)
+
+	cp := []string{filepath.Join(application.Root, md.Classes)}
+	cp = append(cp, lClassPath...)
+	for _, f := range j {
+		if r, err := filepath.Rel(application.Root, f); err != nil {
+			return Metadata{}, packaging{}, false, err
+		} else if !md.isAdditional(filepath.ToSlash(r)) {
+			cp = append(cp, f)
+		}
+	}
+	cp = append(cp, mClassPath...)
+
+	seen := make(map[string]bool, len(cp))
+	for _, c := range cp {
+		if !seen[c] {
+			seen[c] = true
+			md.ClassPath = append(md.ClassPath, c)
+		}
+	}
+
+	return md, packaging{}, true, nil
+}
+
+// isClassesDirectory returns whether Spring-Boot-Classes is a directory.  The classes of artifacts that have not been
+// exploded are not read.
+func isClassesDirectory(root string, classes string) (bool, error) {
+	i, err := os.Stat(filepath.Join(root, classes))
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return i.IsDir(), nil
+}
+
+// newPackagedMetadata creates a new Metadata for an application that has not been exploded.  A single artifact is the
+// application's classes and its only classpath entry, as in a distribution's start scripts.  Multiple artifacts are
+// each launched with their own classpath.
+func newPackagedMetadata(root string, p packaging) (Metadata, error) {
+	md := Metadata{}
+
+	if err := properties.LoadMap(p.Artifacts[0].manifest).Decode(&md); err != nil {
+		return Metadata{}, err
+	}
+
+	var err error
+	if md.ArtifactID, err = artifactID(root, md.ArtifactID); err != nil {
+		return Metadata{}, err
+	}
+
+	md.Classes, md.Lib = "", p.Lib
+	if !p.isMultiple() {
+		md.Classes = filepath.ToSlash(p.Artifacts[0].Path)
+	}
+
+	md.AdditionalClassPath, md.ClassPath = nil, nil
+	for _, a := range p.Artifacts {
+		md.ClassPath = append(md.ClassPath, filepath.Join(root, a.Path))
+	}
+
+	return md, nil
+}
+
+// layout infers the Spring-Boot-Classes and Spring-Boot-Lib of an application whose manifest does not declare them.
+// Spring Boot 1.4 and later package classes in BOOT-INF/classes/ and libraries in BOOT-INF/lib/, wars package them
+// in WEB-INF/, and Spring Boot 1.3 and earlier jars package classes at the root and libraries in lib/.
+func layout(root string, version string) (string, string, error) {
+	for _, l := range []struct {
+		classes string
+		lib     string
+	}{
+		{"BOOT-INF/classes/", "BOOT-INF/lib/"},
+		{"WEB-INF/classes/", "WEB-INF/lib/"},
+	} {
+		if exists, err := helper.FileExists(filepath.Join(root, l.classes)); err != nil {
+			return "", "", err
+		} else if exists {
+			return l.classes, l.lib, nil
+		}
+	}
+
+	if !strings.HasPrefix(version, "1.") {
+		return "", "", nil
+	}
+
+	if exists, err := helper.FileExists(filepath.Join(root, "lib")); err != nil {
+		return "", "", err
+	} else if !exists {
+		return "", "", nil
+	}
+
+	return "", "lib/", nil
+}
+
+// artifactID returns the artifactId from the application's Maven pom.properties, falling back to the manifest's
+// Implementation-Title if there is not exactly one.
+func artifactID(root string, title string) (string, error) {
+	f, err := filepath.Glob(filepath.Join(root, "META-INF", "maven", "*", "*", "pom.properties"))
+	if err != nil {
+		return "", err
+	}
+
+	if len(f) != 1 {
+		return title, nil
+	}
+
+	p, err := properties.LoadFile(f[0], properties.UTF8)
+	if err != nil {
+		return "", fmt.Errorf("unable to read %s: %w", f[0], err)
 	}
 
-	md.ClassPath = append(md.ClassPath, filepath.Join(application.Root, md.Classes))
-	md.ClassPath = append(md.ClassPath, j...)
-	return md, true, nil
+	return p.GetString("artifactId", title), nil
 }
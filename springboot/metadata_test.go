@@ -68,5 +68,193 @@ Spring-Boot-Version: test-version`)
 				Version:    "test-version",
 			}))
 		})
+
+		it("resolves loader.path and Class-Path", func() {
+			test.TouchFile(t, filepath.Join(f.Detect.Application.Root, "test-lib", "test.jar"))
+			test.TouchFile(t, filepath.Join(f.Detect.Application.Root, "plugins", "plugin.jar"))
+			test.TouchFile(t, filepath.Join(f.Detect.Application.Root, "ext", "extension.jar"))
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "test-classes", "loader.properties"),
+				"loader.path=plugins,${HOME}/plugins")
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "META-INF", "MANIFEST.MF"),
+				`
+Class-Path: ext/extension.jar missing.jar
+Main-Class: org.springframework.boot.loader.PropertiesLauncher
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+
+			md, ok, err := springboot.NewMetadata(f.Detect.Application, f.Detect.Logger)
+			g.Expect(ok).To(gomega.BeTrue())
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			g.Expect(md.AdditionalClassPath).To(gomega.Equal([]string{"plugins", "ext/extension.jar"}))
+			g.Expect(md.ClassPath).To(gomega.Equal([]string{
+				filepath.Join(f.Detect.Application.Root, "test-classes"),
+				filepath.Join(f.Detect.Application.Root, "plugins"),
+				filepath.Join(f.Detect.Application.Root, "plugins", "plugin.jar"),
+				filepath.Join(f.Detect.Application.Root, "test-lib", "test.jar"),
+				filepath.Join(f.Detect.Application.Root, "ext", "extension.jar"),
+			}))
+		})
+
+		it("ignores loader.path without PropertiesLauncher", func() {
+			test.TouchFile(t, filepath.Join(f.Detect.Application.Root, "plugins", "plugin.jar"))
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "test-classes", "loader.properties"), "loader.path=plugins")
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "META-INF", "MANIFEST.MF"),
+				`
+Main-Class: org.springframework.boot.loader.JarLauncher
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+
+			md, ok, err := springboot.NewMetadata(f.Detect.Application, f.Detect.Logger)
+			g.Expect(ok).To(gomega.BeTrue())
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			g.Expect(md.AdditionalClassPath).To(gomega.BeEmpty())
+			g.Expect(md.ClassPath).To(gomega.Equal([]string{
+				filepath.Join(f.Detect.Application.Root, "test-classes"),
+				filepath.Join(f.Detect.Application.Root, "plugins", "plugin.jar"),
+			}))
+		})
+
+		it("resolves Loader-Path", func() {
+			test.TouchFile(t, filepath.Join(f.Detect.Application.Root, "plugins", "plugin.jar"))
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "META-INF", "MANIFEST.MF"),
+				`
+Loader-Path: plugins
+Main-Class: org.springframework.boot.loader.launch.PropertiesLauncher
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+
+			md, ok, err := springboot.NewMetadata(f.Detect.Application, f.Detect.Logger)
+			g.Expect(ok).To(gomega.BeTrue())
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			g.Expect(md.AdditionalClassPath).To(gomega.Equal([]string{"plugins"}))
+		})
+
+		it("decodes Class-Path", func() {
+			test.TouchFile(t, filepath.Join(f.Detect.Application.Root, "ext dir", "extension.jar"))
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "META-INF", "MANIFEST.MF"), "%s",
+				`
+Class-Path: ext%20dir/extension.jar
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+
+			md, ok, err := springboot.NewMetadata(f.Detect.Application, f.Detect.Logger)
+			g.Expect(ok).To(gomega.BeTrue())
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			g.Expect(md.AdditionalClassPath).To(gomega.Equal([]string{"ext dir/extension.jar"}))
+		})
+
+		it("infers Spring Boot 1.x layout", func() {
+			test.TouchFile(t, filepath.Join(f.Detect.Application.Root, "lib", "test.jar"))
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "META-INF", "MANIFEST.MF"),
+				`
+Start-Class: test-start-class
+Spring-Boot-Version: 1.3.8.RELEASE`)
+
+			md, ok, err := springboot.NewMetadata(f.Detect.Application, f.Detect.Logger)
+			g.Expect(ok).To(gomega.BeTrue())
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			g.Expect(md).To(gomega.Equal(springboot.Metadata{
+				ClassPath: []string{
+					f.Detect.Application.Root,
+					filepath.Join(f.Detect.Application.Root, "lib", "test.jar"),
+				},
+				Lib:        "lib/",
+				StartClass: "test-start-class",
+				Version:    "1.3.8.RELEASE",
+			}))
+		})
+
+		it("infers BOOT-INF layout", func() {
+			test.TouchFile(t, filepath.Join(f.Detect.Application.Root, "BOOT-INF", "classes", "Test.class"))
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "META-INF", "MANIFEST.MF"),
+				`
+Start-Class: test-start-class
+Spring-Boot-Version: 1.5.22.RELEASE`)
+
+			md, _, err := springboot.NewMetadata(f.Detect.Application, f.Detect.Logger)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(md.Classes).To(gomega.Equal("BOOT-INF/classes/"))
+			g.Expect(md.Lib).To(gomega.Equal("BOOT-INF/lib/"))
+		})
+
+		it("reads distribution metadata from boot jar", func() {
+			test.CopyFile(t, filepath.Join("testdata", "test-boot-1.0.0.jar"),
+				filepath.Join(f.Detect.Application.Root, "lib", "test-boot-1.0.0.jar"))
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "bin", "test-boot"), "#!/bin/sh")
+
+			md, ok, err := springboot.NewMetadata(f.Detect.Application, f.Detect.Logger)
+			g.Expect(ok).To(gomega.BeTrue())
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			g.Expect(md).To(gomega.Equal(springboot.Metadata{
+				ArtifactID: "test-boot",
+				Classes:    "lib/test-boot-1.0.0.jar",
+				ClassPath: []string{
+					filepath.Join(f.Detect.Application.Root, "lib", "test-boot-1.0.0.jar"),
+				},
+				Lib:        "lib/",
+				StartClass: "test.Application",
+				Version:    "2.7.18",
+			}))
+		})
+
+		it("rejects distribution with Spring Boot artifacts in the application root", func() {
+			test.CopyFile(t, filepath.Join("testdata", "test-boot-1.0.0.jar"),
+				filepath.Join(f.Detect.Application.Root, "lib", "test-boot-1.0.0.jar"))
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "bin", "test-boot"), "#!/bin/sh")
+			test.CopyFile(t, filepath.Join("testdata", "test-worker-2.0.0.jar"),
+				filepath.Join(f.Detect.Application.Root, "test-worker-2.0.0.jar"))
+
+			_, _, err := springboot.NewMetadata(f.Detect.Application, f.Detect.Logger)
+			g.Expect(err).To(gomega.MatchError(
+				"found both a distribution with lib/test-boot-1.0.0.jar and Spring Boot artifacts in the application root: test-worker-2.0.0.jar"))
+		})
+
+		it("rejects exploded application with Spring Boot artifacts in the application root", func() {
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "META-INF", "MANIFEST.MF"),
+				`
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+			test.CopyFile(t, filepath.Join("testdata", "test-worker-2.0.0.jar"),
+				filepath.Join(f.Detect.Application.Root, "test-worker-2.0.0.jar"))
+
+			_, _, err := springboot.NewMetadata(f.Detect.Application, f.Detect.Logger)
+			g.Expect(err).To(gomega.MatchError(
+				"found both an exploded Spring Boot application and Spring Boot artifacts in the application root: test-worker-2.0.0.jar"))
+		})
+
+		it("parses artifact ID", func() {
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "META-INF", "MANIFEST.MF"),
+				`
+Implementation-Title: test-title
+Spring-Boot-Version: test-version`)
+
+			md, _, err := springboot.NewMetadata(f.Detect.Application, f.Detect.Logger)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(md.ArtifactID).To(gomega.Equal("test-title"))
+
+			test.WriteFile(t, filepath.Join(f.Detect.Application.Root, "META-INF", "maven", "test-group", "test-artifact", "pom.properties"),
+				`
+groupId=test-group
+artifactId=test-artifact
+version=1.0.0`)
+
+			md, _, err = springboot.NewMetadata(f.Detect.Application, f.Detect.Logger)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(md.ArtifactID).To(gomega.Equal("test-artifact"))
+		})
 	}, spec.Report(report.Terminal{}))
 }
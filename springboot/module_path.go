@@ -0,0 +1,211 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/logger"
+)
+
+// modules describes launching a modular application, whose classes contain a module-info.class, on the module path.
+type modules struct {
+	// Module is the name of the application's module.  Empty if the application is not modular.
+	Module string
+
+	// Reasons are the reasons a modular application is launched on the classpath instead of the module path.
+	Reasons []string
+}
+
+// Enabled returns whether the application is launched on the module path.
+func (m modules) Enabled() bool {
+	return m.Module != "" && len(m.Reasons) == 0
+}
+
+// Log logs whether a modular application is launched on the module path and, if not, why.
+func (m modules) Log(logger logger.Logger) {
+	if m.Module == "" {
+		return
+	}
+
+	if m.Enabled() {
+		logger.Header("Launching module %s on the module path", m.Module)
+		return
+	}
+
+	logger.HeaderWarning("Launching module %s on the classpath", m.Module)
+	for _, r := range m.Reasons {
+		logger.BodyWarning(r)
+	}
+}
+
+// newModules determines whether an application can be launched on the module path.  Every dependency on the classpath
+// must be a named module or declare an Automatic-Module-Name, and no package may be split across dependencies.
+func (s SpringBoot) newModules() (modules, error) {
+	if len(s.packaging.Artifacts) > 0 || s.nativeImage.Enabled {
+		return modules{}, nil
+	}
+
+	classes := filepath.Join(s.application.Root, s.Metadata.Classes)
+
+	f, err := os.Open(filepath.Join(classes, "module-info.class"))
+	if os.IsNotExist(err) {
+		return modules{}, nil
+	} else if err != nil {
+		return modules{}, err
+	}
+	defer f.Close()
+
+	n, err := moduleName(f)
+	if err != nil {
+		return modules{}, fmt.Errorf("unable to read %s: %w", f.Name(), err)
+	}
+
+	m := modules{Module: n}
+
+	if s.Metadata.StartClass == "" {
+		m.Reasons = append(m.Reasons, "Start-Class is not declared")
+	}
+
+	if len(s.Metadata.AdditionalClassPath) > 0 {
+		m.Reasons = append(m.Reasons, "loader.path and Class-Path entries are not supported on the module path")
+	}
+
+	if s.cds.Enabled {
+		m.Reasons = append(m.Reasons, "class data sharing archives are generated on the classpath")
+	}
+
+	packages, err := classPackages(classes)
+	if err != nil {
+		return modules{}, err
+	}
+
+	// owners maps each package to the first classpath entry that contains it
+	owners := make(map[string]string, len(packages))
+	for _, p := range packages {
+		owners[p] = s.Metadata.Classes
+	}
+
+	var splits []splitPackage
+	declared := map[string]string{n: s.Metadata.Classes}
+	for _, c := range s.Metadata.ClassPath {
+		if c == classes {
+			continue
+		}
+
+		name := filepath.Base(c)
+		d, ok := s.classPathDependency(c)
+		if !ok || d.Module == "" {
+			m.Reasons = append(m.Reasons,
+				fmt.Sprintf("%s is not a named module and does not declare Automatic-Module-Name", name))
+			continue
+		}
+
+		if other, ok := declared[d.Module]; ok {
+			m.Reasons = append(m.Reasons, fmt.Sprintf("%s and %s both declare module %s", other, name, d.Module))
+			continue
+		}
+		declared[d.Module] = name
+
+		seen := make(map[string]bool)
+		for _, cl := range d.classes {
+			p := strings.ReplaceAll(path.Dir(cl), "/", ".")
+			if p == "." || seen[p] {
+				continue
+			}
+			seen[p] = true
+
+			other, ok := owners[p]
+			if !ok {
+				owners[p] = name
+				continue
+			}
+
+			splits = addSplitPackage(splits, other, name, p)
+		}
+	}
+
+	for _, p := range splits {
+		sort.Strings(p.Packages)
+		m.Reasons = append(m.Reasons, fmt.Sprintf("%s and %s both contain packages %s",
+			p.Dependencies[0], p.Dependencies[1], strings.Join(p.Packages, ", ")))
+	}
+
+	return m, nil
+}
+
+// classPathDependency returns the dependency of a classpath entry.  OK is false if the entry is not a dependency.
+func (s SpringBoot) classPathDependency(path string) (JARDependency, bool) {
+	for _, d := range s.jarDependencies {
+		if d.matches(path) {
+			return d, true
+		}
+	}
+
+	return JARDependency{}, false
+}
+
+// addSplitPackage records that a package is contained in two classpath entries, grouping the packages of each pair.
+func addSplitPackage(splits []splitPackage, a string, b string, pkg string) []splitPackage {
+	for i, s := range splits {
+		if s.Dependencies[0] == a && s.Dependencies[1] == b {
+			splits[i].Packages = append(splits[i].Packages, pkg)
+			return splits
+		}
+	}
+
+	return append(splits, splitPackage{Dependencies: [2]string{a, b}, Packages: []string{pkg}})
+}
+
+// classPackages returns the packages of the classes in a classes directory.
+func classPackages(root string) ([]string, error) {
+	seen := make(map[string]bool)
+	var packages []string
+
+	if err := filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || filepath.Ext(file) != ".class" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+
+		p := path.Dir(filepath.ToSlash(rel))
+		if p == "." || strings.HasPrefix(p, "META-INF/") || seen[p] {
+			return nil
+		}
+		seen[p] = true
+		packages = append(packages, strings.ReplaceAll(p, "/", "."))
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return packages, nil
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/layers"
+)
+
+// NativeImageDependency is the name of the layer containing the native executable.
+const NativeImageDependency = "native-image"
+
+// NativeImage is the configuration of compiling an application into a GraalVM native executable.
+type NativeImage struct {
+	// Enabled indicates whether the application is compiled into a native executable.
+	Enabled bool
+
+	// Args are the additional arguments passed to native-image.
+	Args []string
+
+	// Path is the path to the native-image executable.
+	Path string
+}
+
+// NewNativeImage creates a new NativeImage from the $BP_SPRING_BOOT_NATIVE_IMAGE and
+// $BP_SPRING_BOOT_NATIVE_IMAGE_ARGS environment variables.  If enabled, native-image must be on $PATH, typically
+// contributed by a preceding buildpack.
+func NewNativeImage() (NativeImage, error) {
+	n := NativeImage{}
+
+	var err error
+	if n.Enabled, err = boolEnv("BP_SPRING_BOOT_NATIVE_IMAGE"); err != nil {
+		return NativeImage{}, err
+	}
+
+	if !n.Enabled {
+		return n, nil
+	}
+
+	if s, ok := os.LookupEnv("BP_SPRING_BOOT_NATIVE_IMAGE_ARGS"); ok {
+		n.Args = strings.Fields(s)
+	}
+
+	p, err := exec.LookPath("native-image")
+	if err != nil {
+		return NativeImage{}, fmt.Errorf("native-image must be on $PATH, typically contributed by a preceding buildpack, " +
+			"when $BP_SPRING_BOOT_NATIVE_IMAGE is true")
+	}
+	n.Path = p
+
+	return n, nil
+}
+
+type nativeImageMetadata struct {
+	Args       []string `toml:"args"`
+	ClassPath  []string `toml:"classpath"`
+	Digest     string   `toml:"digest"`
+	StartClass string   `toml:"start-class"`
+}
+
+func (n nativeImageMetadata) Identity() (string, string) {
+	return "Native Image", ""
+}
+
+// contributeNativeImage compiles the application into a native executable in a layer marked launch and returns the
+// path to the executable.  The configuration in META-INF/native-image of every classpath entry is applied by
+// native-image itself.  The merged configuration directory adds to it, so it cannot override an entry of any source.
+func (s SpringBoot) contributeNativeImage() (string, error) {
+	layer := s.layers.Layer(NativeImageDependency)
+	executable := filepath.Join(layer.Root, "application")
+
+	d, err := s.digest()
+	if err != nil {
+		return "", err
+	}
+
+	md := nativeImageMetadata{
+		Args:       s.nativeImage.Args,
+		ClassPath:  s.Metadata.ClassPath,
+		Digest:     d,
+		StartClass: s.Metadata.StartClass,
+	}
+
+	s.nativeImageConfiguration.Log(s.logger)
+
+	if err := layer.Contribute(md, func(layer layers.Layer) error {
+		if err := os.RemoveAll(layer.Root); err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(layer.Root, 0755); err != nil {
+			return err
+		}
+
+		args := []string{"--no-fallback"}
+		if len(s.nativeImageConfiguration.Files) > 0 {
+			d, err := s.nativeImageConfiguration.Contribute(s.layers.Layer(NativeImageConfigurationDependency))
+			if err != nil {
+				return err
+			}
+
+			args = append(args, fmt.Sprintf("-H:ConfigurationFileDirectories=%s", d))
+		}
+		args = append(args, s.nativeImage.Args...)
+		args = append(args, "-cp", strings.Join(s.Metadata.ClassPath, string(filepath.ListSeparator)),
+			"-o", executable, s.Metadata.StartClass)
+
+		layer.Logger.Body("Compiling %s", s.Metadata.StartClass)
+		if err := s.runner.Run(s.nativeImage.Path, s.application.Root, args...); err != nil {
+			return fmt.Errorf("unable to compile native executable: %w", err)
+		}
+
+		return nil
+	}, layers.Launch); err != nil {
+		return "", err
+	}
+
+	return executable, nil
+}
+
+// digest returns a digest of the contents of the classpath, invalidating cached contributions when any class or
+// dependency changes.
+func (s SpringBoot) digest() (string, error) {
+	h := sha256.New()
+
+	for _, c := range s.Metadata.ClassPath {
+		if err := filepath.Walk(c, func(path string, info os.FileInfo, err error) error {
+			if os.IsNotExist(err) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			if info.IsDir() {
+				return nil
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			_, _ = io.WriteString(h, path)
+			_, err = io.Copy(h, f)
+			return err
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
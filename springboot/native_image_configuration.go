@@ -0,0 +1,291 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/helper"
+	"github.com/cloudfoundry/libcfbuildpack/v2/layers"
+	"github.com/cloudfoundry/libcfbuildpack/v2/logger"
+)
+
+// NativeImageConfigurationDependency is the name of the layer containing the merged native-image configuration.
+const NativeImageConfigurationDependency = "native-image-configuration"
+
+// nativeImageFile is a JSON configuration file in META-INF/native-image.
+type nativeImageFile struct {
+	// Source describes where the file was read from.
+	Source string
+
+	// Name is the name of the file, e.g. reflect-config.json.
+	Name string
+
+	// Content is the content of the file.
+	Content []byte
+}
+
+func isNativeImageFile(name string) bool {
+	return strings.HasPrefix(name, "META-INF/native-image/") && strings.HasSuffix(name, ".json")
+}
+
+// nativeImageConfiguration is the native-image configuration of an application and its dependencies, merged by file
+// name.
+type nativeImageConfiguration struct {
+	// Differences describe entries that are configured differently by more than one source.  native-image also reads
+	// the configuration of every classpath entry itself, so every source's entry still applies and the differences are
+	// only informational.
+	Differences []string
+
+	// Files are the merged configuration files keyed by name.
+	Files map[string]interface{}
+
+	origins map[string]string
+}
+
+// Log reports the differences.
+func (n nativeImageConfiguration) Log(logger logger.Logger) {
+	if len(n.Differences) == 0 {
+		return
+	}
+
+	logger.Header("Found native-image configuration that differs between sources")
+	for _, d := range n.Differences {
+		logger.Body(d)
+	}
+}
+
+type nativeImageConfigurationMetadata struct {
+	Digest string `toml:"digest"`
+}
+
+func (n nativeImageConfigurationMetadata) Identity() (string, string) {
+	return "Native Image Configuration", ""
+}
+
+// Contribute writes the merged configuration files to a layer, returning the directory containing them.
+func (n nativeImageConfiguration) Contribute(layer layers.Layer) (string, error) {
+	names := make([]string, 0, len(n.Files))
+	for k := range n.Files {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	content := make(map[string][]byte, len(names))
+	h := sha256.New()
+	for _, k := range names {
+		b, err := json.MarshalIndent(n.Files[k], "", "  ")
+		if err != nil {
+			return "", err
+		}
+
+		content[k] = b
+		_, _ = fmt.Fprintf(h, "%s\n%s\n", k, b)
+	}
+
+	md := nativeImageConfigurationMetadata{hex.EncodeToString(h.Sum(nil))}
+	if err := layer.Contribute(md, func(layer layers.Layer) error {
+		if err := os.RemoveAll(layer.Root); err != nil {
+			return err
+		}
+
+		for _, k := range names {
+			layer.Logger.Body("Writing %s", k)
+			if err := helper.WriteFile(filepath.Join(layer.Root, k), 0644, "%s", content[k]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return "", err
+	}
+
+	return layer.Root, nil
+}
+
+// newNativeImageConfiguration merges configuration files in order, failing if any is not well-formed JSON.
+func newNativeImageConfiguration(files []nativeImageFile) (nativeImageConfiguration, error) {
+	n := nativeImageConfiguration{Files: make(map[string]interface{}), origins: make(map[string]string)}
+
+	for _, f := range files {
+		var v interface{}
+
+		d := json.NewDecoder(bytes.NewReader(f.Content))
+		d.UseNumber()
+		if err := d.Decode(&v); err != nil {
+			return nativeImageConfiguration{}, fmt.Errorf("%s is not well-formed JSON: %w", f.Source, err)
+		}
+
+		existing, ok := n.Files[f.Name]
+		if !ok {
+			n.Files[f.Name] = v
+			n.origins[f.Name] = f.Source
+			continue
+		}
+
+		n.Files[f.Name] = n.merge(existing, v, f.Name, f.Source)
+	}
+
+	return n, nil
+}
+
+// merge merges b into a.  Array elements are identified by their name, type, and condition, if any, and otherwise by
+// their content.
+func (n *nativeImageConfiguration) merge(a interface{}, b interface{}, path string, source string) interface{} {
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		for k, v := range y {
+			p := fmt.Sprintf("%s.%s", path, k)
+
+			if e, ok := x[k]; ok {
+				x[k] = n.merge(e, v, p, source)
+			} else {
+				x[k] = v
+				n.origins[p] = source
+			}
+		}
+
+		return x
+
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+
+		index := make(map[string]int, len(x))
+		for i, e := range x {
+			index[identity(e)] = i
+		}
+
+		for _, v := range y {
+			id := identity(v)
+			p := fmt.Sprintf("%s[%s]", path, id)
+
+			i, ok := index[id]
+			if !ok {
+				index[id] = len(x)
+				x = append(x, v)
+				n.origins[p] = source
+			} else if canonical(x[i]) != canonical(v) {
+				n.difference(p, source)
+			}
+		}
+
+		return x
+	}
+
+	if canonical(a) != canonical(b) {
+		n.difference(path, source)
+	}
+
+	return a
+}
+
+func (n *nativeImageConfiguration) difference(path string, source string) {
+	o := path
+	for {
+		if s, ok := n.origins[o]; ok {
+			n.Differences = append(n.Differences, fmt.Sprintf("%s in %s differs from %s", path, source, s))
+			return
+		}
+
+		i := strings.LastIndexAny(o, ".[")
+		if i < 0 {
+			n.Differences = append(n.Differences, fmt.Sprintf("%s in %s differs from an earlier source", path, source))
+			return
+		}
+		o = o[:i]
+	}
+}
+
+func canonical(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func identity(v interface{}) string {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return canonical(v)
+	}
+
+	for _, k := range []string{"name", "type"} {
+		if s, ok := m[k].(string); ok {
+			if c, ok := m["condition"]; ok {
+				return fmt.Sprintf("%s %s", s, canonical(c))
+			}
+			return s
+		}
+	}
+
+	return canonical(v)
+}
+
+// readNativeImageFiles reads the META-INF/native-image JSON configuration files of the application's classes.
+func readNativeImageFiles(root string, classes string) ([]nativeImageFile, error) {
+	var files []nativeImageFile
+
+	d := filepath.Join(root, classes, "META-INF", "native-image")
+	if exists, err := helper.FileExists(d); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, nil
+	}
+
+	if err := filepath.Walk(d, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, nativeImageFile{Source: rel, Name: filepath.Base(path), Content: b})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+// allowedAgents are the Java agents that are attached at launch when packaged in an application.
+var allowedAgents = regexp.MustCompile(`^(aspectjweaver|spring-instrument)$`)
+
+// PackagedAgents is the configuration of the Java agents packaged in an application.
+type PackagedAgents struct {
+	// Allowed are the artifact names of the Java agents that are attached at launch in addition to aspectjweaver and
+	// spring-instrument.
+	Allowed []string
+}
+
+// NewPackagedAgents creates a new PackagedAgents from the $BP_SPRING_BOOT_ALLOWED_AGENTS environment variable.
+func NewPackagedAgents() PackagedAgents {
+	p := PackagedAgents{}
+
+	if a, ok := os.LookupEnv("BP_SPRING_BOOT_ALLOWED_AGENTS"); ok {
+		for _, n := range strings.Split(a, ",") {
+			if n = strings.TrimSpace(n); n != "" {
+				p.Allowed = append(p.Allowed, n)
+			}
+		}
+	}
+
+	return p
+}
+
+// allows returns whether a packaged Java agent is attached at launch.
+func (p PackagedAgents) allows(name string) bool {
+	return allowedAgents.MatchString(name) || contains(p.Allowed, name)
+}
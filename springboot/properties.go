@@ -0,0 +1,136 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/layers"
+)
+
+const propertyPrefix = "BP_SPRING_PROPERTY_"
+
+// Properties is the build-time Spring configuration contributed to the launch environment.
+type Properties struct {
+	// ApplicationJSON is the SPRING_APPLICATION_JSON contributed to the launch environment.
+	ApplicationJSON string `toml:"application-json"`
+
+	// ProfilesActive is the SPRING_PROFILES_ACTIVE contributed to the launch environment.
+	ProfilesActive string `toml:"profiles-active"`
+}
+
+// Contribute writes the properties as defaults in the launch environment, allowing them to be overridden at launch.
+func (p Properties) Contribute(layer layers.Layer) error {
+	if p.ProfilesActive != "" {
+		if err := layer.DefaultLaunchEnv("SPRING_PROFILES_ACTIVE", "%s", p.ProfilesActive); err != nil {
+			return err
+		}
+	}
+
+	if p.ApplicationJSON != "" {
+		if err := layer.DefaultLaunchEnv("SPRING_APPLICATION_JSON", "%s", p.ApplicationJSON); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewProperties creates a new Properties from the BP_SPRING_PROFILES_ACTIVE, BP_SPRING_APPLICATION_JSON, and
+// BP_SPRING_PROPERTY_* environment variables.  Each BP_SPRING_PROPERTY_* variable is merged into the
+// BP_SPRING_APPLICATION_JSON document, with '_' in its name mapping to '.' and '__' mapping to '-'.
+func NewProperties() (Properties, error) {
+	p := Properties{}
+
+	if s, ok := os.LookupEnv("BP_SPRING_PROFILES_ACTIVE"); ok {
+		p.ProfilesActive = strings.TrimSpace(s)
+	}
+
+	document := make(map[string]interface{})
+
+	if s, ok := os.LookupEnv("BP_SPRING_APPLICATION_JSON"); ok && strings.TrimSpace(s) != "" {
+		if err := json.Unmarshal([]byte(s), &document); err != nil {
+			return Properties{}, fmt.Errorf("BP_SPRING_APPLICATION_JSON is not a JSON object: %w", err)
+		}
+	}
+
+	var names []string
+	for _, e := range os.Environ() {
+		if n := strings.SplitN(e, "=", 2)[0]; strings.HasPrefix(n, propertyPrefix) {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+
+	for _, n := range names {
+		path := propertyName(n)
+		for _, k := range path {
+			if k == "" {
+				return Properties{}, fmt.Errorf("%s does not map to a valid property name", n)
+			}
+		}
+
+		if err := merge(document, path, os.Getenv(n)); err != nil {
+			return Properties{}, fmt.Errorf("unable to merge %s into SPRING_APPLICATION_JSON: %w", n, err)
+		}
+	}
+
+	if len(document) > 0 {
+		b, err := json.Marshal(document)
+		if err != nil {
+			return Properties{}, err
+		}
+
+		p.ApplicationJSON = string(b)
+	}
+
+	return p, nil
+}
+
+// merge sets the value at a dotted property path, creating intermediate objects as required.
+func merge(document map[string]interface{}, path []string, value string) error {
+	for i, k := range path[:len(path)-1] {
+		switch c := document[k].(type) {
+		case nil:
+			n := make(map[string]interface{})
+			document[k] = n
+			document = n
+		case map[string]interface{}:
+			document = c
+		default:
+			return fmt.Errorf("%s is already set to a non-object value", strings.Join(path[:i+1], "."))
+		}
+	}
+
+	k := path[len(path)-1]
+	if _, ok := document[k].(map[string]interface{}); ok {
+		return fmt.Errorf("%s is already set to an object value", strings.Join(path, "."))
+	}
+
+	document[k] = value
+	return nil
+}
+
+func propertyName(variable string) []string {
+	s := strings.ToLower(strings.TrimPrefix(variable, propertyPrefix))
+	s = strings.ReplaceAll(s, "__", "-")
+	return strings.Split(s, "_")
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot_test
+
+import (
+	"testing"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/test"
+	"github.com/cloudfoundry/spring-boot-cnb/springboot"
+	"github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestProperties(t *testing.T) {
+	spec.Run(t, "Properties", func(t *testing.T, when spec.G, it spec.S) {
+
+		g := gomega.NewWithT(t)
+
+		it("returns empty properties by default", func() {
+			g.Expect(springboot.NewProperties()).To(gomega.Equal(springboot.Properties{}))
+		})
+
+		it("reads active profiles", func() {
+			defer test.ReplaceEnv(t, "BP_SPRING_PROFILES_ACTIVE", "alpha,bravo")()
+
+			g.Expect(springboot.NewProperties()).To(gomega.Equal(springboot.Properties{ProfilesActive: "alpha,bravo"}))
+		})
+
+		it("maps properties to application JSON", func() {
+			defer test.ReplaceEnv(t, "BP_SPRING_PROPERTY_SERVER_PORT", "8081")()
+			defer test.ReplaceEnv(t, "BP_SPRING_PROPERTY_SERVER_ADDRESS", "127.0.0.1")()
+			defer test.ReplaceEnv(t, "BP_SPRING_PROPERTY_SPRING_JPA_OPEN__IN__VIEW", "false")()
+
+			g.Expect(springboot.NewProperties()).To(gomega.Equal(springboot.Properties{
+				ApplicationJSON: `{"server":{"address":"127.0.0.1","port":"8081"},"spring":{"jpa":{"open-in-view":"false"}}}`,
+			}))
+		})
+
+		it("merges properties into application JSON", func() {
+			defer test.ReplaceEnv(t, "BP_SPRING_APPLICATION_JSON", `{"server":{"port":8080,"ssl":{"enabled":true}},"alpha":1}`)()
+			defer test.ReplaceEnv(t, "BP_SPRING_PROPERTY_SERVER_PORT", "8081")()
+
+			g.Expect(springboot.NewProperties()).To(gomega.Equal(springboot.Properties{
+				ApplicationJSON: `{"alpha":1,"server":{"port":"8081","ssl":{"enabled":true}}}`,
+			}))
+		})
+
+		it("rejects invalid application JSON", func() {
+			defer test.ReplaceEnv(t, "BP_SPRING_APPLICATION_JSON", `[1]`)()
+
+			_, err := springboot.NewProperties()
+			g.Expect(err).To(gomega.MatchError(gomega.HavePrefix("BP_SPRING_APPLICATION_JSON is not a JSON object")))
+		})
+
+		it("rejects conflicting properties", func() {
+			defer test.ReplaceEnv(t, "BP_SPRING_APPLICATION_JSON", `{"server":8080}`)()
+			defer test.ReplaceEnv(t, "BP_SPRING_PROPERTY_SERVER_PORT", "8081")()
+
+			_, err := springboot.NewProperties()
+			g.Expect(err).To(gomega.MatchError("unable to merge BP_SPRING_PROPERTY_SERVER_PORT into SPRING_APPLICATION_JSON: server is already set to a non-object value"))
+		})
+
+		it("rejects invalid property names", func() {
+			defer test.ReplaceEnv(t, "BP_SPRING_PROPERTY__SERVER", "8081")()
+
+			_, err := springboot.NewProperties()
+			g.Expect(err).To(gomega.MatchError("BP_SPRING_PROPERTY__SERVER does not map to a valid property name"))
+		})
+	}, spec.Report(report.Terminal{}))
+}
@@ -23,6 +23,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/buildpacks/libbuildpack/v2/application"
 	"github.com/cloudfoundry/libcfbuildpack/v2/build"
@@ -30,27 +31,148 @@ import (
 	"github.com/cloudfoundry/libcfbuildpack/v2/helper"
 	"github.com/cloudfoundry/libcfbuildpack/v2/layers"
 	"github.com/cloudfoundry/libcfbuildpack/v2/logger"
+	"github.com/cloudfoundry/libcfbuildpack/v2/runner"
+	"github.com/cloudfoundry/spring-boot-cnb/process"
 	"github.com/mitchellh/mapstructure"
 )
 
 // Dependency indicates that an application is a Spring Boot application.
 const Dependency = "spring-boot"
 
+// loader is the package of the Spring Boot launcher classes packaged at the root of an application.
+const loader = "org/springframework/boot/loader/"
+
 // SpringBoot represents a Spring Boot JVM application.
 type SpringBoot struct {
 	// Metadata is metadata about the Spring Boot application.
 	Metadata Metadata
 
-	application application.Application
-	layer       layers.Layer
-	layers      layers.Layers
-	logger      logger.Logger
+	// Properties is the build-time Spring configuration contributed to the launch environment.
+	Properties Properties
+
+	agents                   []string
+	application              application.Application
+	cds                      CDS
+	configurationFiles       configurationFiles
+	conflictPolicy           ConflictPolicy
+	conflicts                conflicts
+	debug                    Debug
+	devTools                 DevTools
+	excluded                 JARDependencies
+	ignoredAgents            JARDependencies
+	jakarta                  JakartaReport
+	jarDependencies          JARDependencies
+	jreModules               jreModules
+	layer                    layers.Layer
+	layers                   layers.Layers
+	logger                   logger.Logger
+	mismatches               []mismatch
+	modules                  modules
+	nativeImage              NativeImage
+	nativeImageConfiguration nativeImageConfiguration
+	packaging                packaging
+	processes                process.Configuration
+	runner                   runner.Runner
+	thin                     thin
+	support                  Support
+	supportPolicy            SupportPolicy
+	versionPolicy            VersionPolicy
 }
 
 // Contribute makes the contribution to build, cache, and launch.
 func (s SpringBoot) Contribute() error {
-	if err := s.layer.Contribute(s.Metadata, func(layer layers.Layer) error {
-		return layer.PrependPathSharedEnv("CLASSPATH", strings.Join(s.Metadata.ClassPath, string(filepath.ListSeparator)))
+	s.support.Log(s.logger)
+
+	if err := s.supportPolicy.check(s.support, s.Metadata.Version, s.logger); err != nil {
+		return err
+	}
+
+	if s.conflicts.Len() > 0 {
+		s.conflicts.Log(s.logger)
+
+		if s.conflictPolicy.Fail {
+			return fmt.Errorf("found %d duplicate dependencies and %d split packages in %s",
+				len(s.conflicts.Duplicates), len(s.conflicts.SplitPackages), s.Metadata.Lib)
+		}
+
+		s.logger.Body("Set $BP_SPRING_BOOT_FAIL_ON_CONFLICTS to true to fail the build")
+	}
+
+	if len(s.mismatches) > 0 {
+		logMismatches(s.mismatches, s.Metadata.Version, s.logger)
+
+		if s.versionPolicy.Fail {
+			return fmt.Errorf("found %d Spring dependencies that do not match Spring Boot %s",
+				len(s.mismatches), s.Metadata.Version)
+		}
+
+		s.logger.Body("Set $BP_SPRING_BOOT_FAIL_ON_VERSION_MISMATCH to true to fail the build")
+	}
+
+	if len(s.excluded) > 0 {
+		s.logger.HeaderWarning("Removing development and test dependencies from CLASSPATH")
+		for _, d := range s.excluded {
+			s.logger.BodyWarning("%s %s (%s)", d.Name, d.Version, d.Scope)
+		}
+		s.logger.Body("Set $BP_SPRING_BOOT_KEEP_DEVELOPMENT_DEPENDENCIES to true to keep them")
+	}
+
+	if s.jakarta.Enabled {
+		logJakarta(s.classPathDependencies(), s.Metadata.Version, s.logger)
+	}
+
+	if len(s.agents) > 0 {
+		s.logger.Header("Attaching Java agents packaged in the application")
+		for _, a := range s.agents {
+			s.logger.Body(a)
+		}
+	}
+
+	if len(s.ignoredAgents) > 0 {
+		s.logger.HeaderWarning("Ignoring Java agents packaged in the application")
+		for _, d := range s.ignoredAgents {
+			if d.Agent {
+				s.logger.BodyWarning("%s %s", d.Name, d.Version)
+			} else {
+				s.logger.BodyWarning("%s %s (Launcher-Agent-Class only, cannot be attached with -javaagent)", d.Name, d.Version)
+			}
+		}
+		s.logger.Body("Add them to $BP_SPRING_BOOT_ALLOWED_AGENTS to attach them")
+	}
+
+	md := layerMetadata{s.Metadata, s.Properties, s.debug, s.agents, s.devTools.remoteSecretHash()}
+	if err := s.layer.Contribute(md, func(layer layers.Layer) error {
+		if err := os.RemoveAll(layer.Root); err != nil {
+			return err
+		}
+
+		if !s.packaging.isMultiple() && !s.modules.Enabled() {
+			cp := strings.Join(s.Metadata.ClassPath, string(filepath.ListSeparator))
+			if err := layer.PrependPathSharedEnv("CLASSPATH", cp); err != nil {
+				return err
+			}
+		}
+
+		if err := s.Properties.Contribute(layer); err != nil {
+			return err
+		}
+
+		if len(s.agents) > 0 {
+			var opts []string
+			for _, a := range s.agents {
+				opts = append(opts, fmt.Sprintf(" -javaagent:%s", a))
+			}
+
+			if err := layer.AppendLaunchEnv("JAVA_OPTS", "%s", strings.Join(opts, "")); err != nil {
+				return err
+			}
+		}
+
+		if err := s.devTools.Contribute(layer); err != nil {
+			return err
+		}
+
+		return s.debug.Contribute(layer)
 	}, layers.Build, layers.Cache, layers.Launch); err != nil {
 		return err
 	}
@@ -60,15 +182,97 @@ func (s SpringBoot) Contribute() error {
 		return err
 	}
 
-	command := fmt.Sprintf("java -cp $CLASSPATH $JAVA_OPTS %s", s.Metadata.StartClass)
+	s.modules.Log(s.logger)
+
+	configuration := s.processes
+	switch s.Metadata.WebApplicationType {
+	case "":
+	case NoneWebApplication:
+		s.logger.Header("Found a non-web application")
+
+		if configuration.Default == "" && !s.packaging.isMultiple() {
+			configuration.Remove = append(append([]string{}, configuration.Remove...), process.Web)
+			s.logger.Body("Not contributing a %s process type, set $BP_SPRING_BOOT_DEFAULT_PROCESS to contribute one",
+				process.Web)
+		}
+	default:
+		s.logger.Header("Found a %s web application using %s", s.Metadata.WebApplicationType,
+			webServerNames[s.Metadata.WebServer])
+
+		if s.Metadata.WebServer == "none" {
+			s.logger.HeaderWarning("No embedded web server found for a %s web application", s.Metadata.WebApplicationType)
+		}
+	}
+
+	command := s.javaCommand()
+	if s.packaging.Script != "" {
+		command = filepath.Join(s.application.Root, s.packaging.Script)
+	}
+
+	if len(s.jreModules.Modules) > 0 {
+		s.logger.Header("Found %d JDK modules used by the application", len(s.jreModules.Modules))
+		if err := s.jreModules.Contribute(s.layers.Layer(JREModulesDependency)); err != nil {
+			return err
+		}
+	}
+
+	if len(s.thin.Artifacts) > 0 {
+		if err := s.thin.Contribute(s.layers.Layer(ThinDependency)); err != nil {
+			return err
+		}
+	}
+
+	if s.nativeImage.Enabled {
+		if command, err = s.contributeNativeImage(); err != nil {
+			return err
+		}
+
+		if s.debug.Enabled || s.devTools.Enabled {
+			s.logger.HeaderWarning("Debug and development process types are not contributed for native executables")
+		}
+	} else if s.cds.Enabled {
+		if err := s.contributeCDS(); err != nil {
+			return err
+		}
+	}
+
+	var processes layers.Processes
+	if s.packaging.isMultiple() {
+		processes, err = configuration.NamedProcessTypes(s.artifactCommands())
+
+		if s.debug.Enabled || s.devTools.Enabled {
+			s.logger.HeaderWarning("Debug and development process types are not contributed for multiple Spring Boot artifacts")
+		}
+	} else {
+		processes, err = configuration.ProcessTypes(Dependency, command)
+	}
+	if err != nil {
+		return err
+	}
+
+	if s.debug.Enabled && !s.nativeImage.Enabled && !s.packaging.isMultiple() {
+		processes, err = s.processes.Add(processes, "debug",
+			s.javaCommand("$"+DebugOpts))
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.devTools.Enabled && !s.nativeImage.Enabled && !s.packaging.isMultiple() {
+		if !s.hasDependency("spring-boot-devtools") {
+			s.logger.HeaderWarning("Development mode is enabled but spring-boot-devtools is not a dependency")
+		}
+
+		processes, err = s.processes.Add(processes, "dev",
+			s.javaCommand(s.devTools.JavaOpts()))
+		if err != nil {
+			return err
+		}
+	}
 
 	return s.layers.WriteApplicationMetadata(layers.Metadata{
-		Slices: slices,
-		Processes: layers.Processes{
-			{Type: "spring-boot", Command: command},
-			{Type: "task", Command: command},
-			{Type: "web", Command: command},
-		},
+		Slices:    slices,
+		Processes: processes,
 	})
 }
 
@@ -83,15 +287,79 @@ func (s SpringBoot) Plan() (buildpackplan.Plan, error) {
 		return buildpackplan.Plan{}, err
 	}
 
-	if d, err := s.dependencies(); err != nil {
-		return buildpackplan.Plan{}, err
-	} else {
-		p.Metadata["dependencies"] = d
+	if len(s.Metadata.AdditionalClassPath) == 0 {
+		delete(p.Metadata, "additional-classpath")
+	}
+
+	if s.Metadata.ArtifactID == "" {
+		delete(p.Metadata, "artifact-id")
+	}
+
+	if s.Metadata.WebApplicationType == "" {
+		delete(p.Metadata, "web-application-type")
+		delete(p.Metadata, "web-server")
+	}
+
+	p.Metadata["dependencies"] = s.jarDependencies
+
+	if len(s.jreModules.Modules) > 0 {
+		p.Metadata["jre-modules"] = s.jreModules.Modules
+	}
+
+	if len(s.configurationFiles.Profiles) > 0 {
+		p.Metadata["profiles"] = s.configurationFiles.Profiles
+	}
+
+	if s.support.ReleaseLine != "" {
+		sp := make(map[string]interface{})
+		if err := mapstructure.Decode(s.support, &sp); err != nil {
+			return buildpackplan.Plan{}, err
+		}
+
+		for k, v := range sp {
+			if v == "" {
+				delete(sp, k)
+			}
+		}
+
+		p.Metadata["support"] = sp
+	}
+
+	if s.packaging.isMultiple() {
+		var artifacts []map[string]interface{}
+		for _, a := range s.packaging.Artifacts {
+			artifacts = append(artifacts, map[string]interface{}{
+				"name":        a.Name,
+				"path":        a.Path,
+				"start-class": a.manifest["Start-Class"],
+				"version":     a.manifest["Spring-Boot-Version"],
+			})
+		}
+
+		p.Metadata["artifacts"] = artifacts
+	}
+
+	if s.devTools.Enabled {
+		d, err := s.devTools.Sync(s.application.Root, s.Metadata.Classes)
+		if err != nil {
+			return buildpackplan.Plan{}, err
+		}
+
+		p.Metadata["devtools"] = d
 	}
 
 	return p, nil
 }
 
+type layerMetadata struct {
+	Metadata
+	Properties
+	Debug
+	Agents []string `toml:"agents"`
+
+	DevToolsRemoteSecret string `toml:"devtools-remote-secret-sha256"`
+}
+
 type result struct {
 	err   error
 	value JARDependency
@@ -101,23 +369,54 @@ func (s SpringBoot) dependencies() (JARDependencies, error) {
 	ch := make(chan result)
 	var wg sync.WaitGroup
 
-	l := filepath.Join(s.application.Root, s.Metadata.Lib)
-	if exists, err := helper.FileExists(l); err != nil {
-		return JARDependencies{}, err
-	} else if !exists {
-		return JARDependencies{}, nil
-	}
+	var paths []string
+	seen := make(map[string]bool)
 
-	if err := filepath.Walk(l, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+	roots := []string{s.Metadata.Lib}
+	roots = append(roots, s.Metadata.AdditionalClassPath...)
+
+	for _, r := range roots {
+		l := filepath.Join(s.application.Root, filepath.FromSlash(r))
+		if exists, err := helper.FileExists(l); err != nil {
+			return JARDependencies{}, err
+		} else if !exists {
+			continue
+		}
+
+		if err := filepath.Walk(l, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if rel, err := filepath.Rel(s.application.Root, path); err != nil {
+				return err
+			} else if s.packaging.contains(rel) || seen[path] {
+				return nil
+			}
+
+			seen[path] = true
+			paths = append(paths, path)
+			return nil
+		}); err != nil {
+			return nil, err
 		}
+	}
+
+	for _, a := range s.thin.Artifacts {
+		paths = append(paths, a.source)
+	}
 
+	if len(paths) == 0 {
+		return JARDependencies{}, nil
+	}
+
+	for _, p := range paths {
 		wg.Add(1)
-		go func() {
+		go func(path string) {
 			defer wg.Done()
 
-			d, ok, err := NewJARDependency(path, s.logger)
+			sc := scan{nativeImage: s.nativeImage.Enabled, references: s.jakarta.Enabled}
+			d, ok, err := newJARDependency(path, sc, s.logger)
 			if err != nil {
 				ch <- result{err: err}
 				return
@@ -126,11 +425,7 @@ func (s SpringBoot) dependencies() (JARDependencies, error) {
 			if ok {
 				ch <- result{value: d}
 			}
-		}()
-
-		return nil
-	}); err != nil {
-		return nil, err
+		}(p)
 	}
 
 	go func() {
@@ -151,20 +446,175 @@ func (s SpringBoot) dependencies() (JARDependencies, error) {
 	return d, nil
 }
 
+// classPathDependencies returns the dependencies that have not been excluded from the classpath.
+func (s SpringBoot) classPathDependencies() JARDependencies {
+	var d JARDependencies
+	for _, j := range s.jarDependencies {
+		if !s.isExcluded(j) {
+			d = append(d, j)
+		}
+	}
+
+	return d
+}
+
+// excludeDependencies removes dependencies with any of the given scopes from the classpath.
+func (s *SpringBoot) excludeDependencies(scopes ...string) {
+	for _, d := range s.jarDependencies {
+		for _, sc := range scopes {
+			if d.Scope == sc {
+				s.excluded = append(s.excluded, d)
+			}
+		}
+	}
+
+	if len(s.excluded) == 0 {
+		return
+	}
+
+	var cp []string
+	for _, c := range s.Metadata.ClassPath {
+		if !s.isExcludedSlice(c) {
+			cp = append(cp, c)
+		}
+	}
+	s.Metadata.ClassPath = cp
+}
+
+// javaAgents partitions the Java agents packaged in the application into those attached at launch and those ignored.
+// Launcher agents are always ignored.
+func (s *SpringBoot) javaAgents(packaged PackagedAgents) {
+	for _, d := range s.jarDependencies {
+		if !d.Agent && !d.LauncherAgent {
+			continue
+		}
+
+		for _, c := range s.Metadata.ClassPath {
+			if !d.matches(c) {
+				continue
+			}
+
+			if d.Agent && packaged.allows(d.Name) {
+				s.agents = append(s.agents, c)
+			} else {
+				s.ignoredAgents = append(s.ignoredAgents, d)
+			}
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s SpringBoot) hasDependency(name string) bool {
+	for _, d := range s.jarDependencies {
+		if d.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s SpringBoot) isExcluded(dependency JARDependency) bool {
+	for _, d := range s.excluded {
+		if d.Name == dependency.Name && d.Version == dependency.Version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// artifactCommands returns the command launching each of multiple Spring Boot artifacts with its own classpath.
+func (s SpringBoot) artifactCommands() map[string]string {
+	commands := make(map[string]string, len(s.packaging.Artifacts))
+
+	for _, a := range s.packaging.Artifacts {
+		commands[a.Name] = fmt.Sprintf("java -cp %s $JAVA_OPTS %s", filepath.Join(s.application.Root, a.Path), a.MainClass)
+	}
+
+	return commands
+}
+
+// javaCommand returns the java command, with additional options, that launches the application on the module path or
+// the classpath.
+func (s SpringBoot) javaCommand(options ...string) string {
+	var c []string
+
+	if s.modules.Enabled() {
+		c = append(c, "java", "--module-path", strings.Join(s.Metadata.ClassPath, string(filepath.ListSeparator)),
+			"--add-modules", "ALL-MODULE-PATH", "$JAVA_OPTS")
+		c = append(c, options...)
+		c = append(c, "-m", fmt.Sprintf("%s/%s", s.modules.Module, s.Metadata.StartClass))
+	} else {
+		c = append(c, "java", "-cp", "$CLASSPATH", "$JAVA_OPTS")
+		c = append(c, options...)
+		c = append(c, s.mainClass())
+	}
+
+	return strings.Join(c, " ")
+}
+
+// mainClass returns the class that launches the application.  A single Spring Boot artifact that has not been
+// exploded is launched by the Spring Boot launcher in it and other applications by their Start-Class.
+func (s SpringBoot) mainClass() string {
+	if len(s.packaging.Artifacts) == 1 {
+		return s.packaging.Artifacts[0].MainClass
+	}
+
+	return s.Metadata.StartClass
+}
+
+func (s SpringBoot) isExcludedSlice(path string) bool {
+	for _, d := range s.excluded {
+		if d.matches(path) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (s SpringBoot) isApplicationSlice(path string) bool {
+	if s.isRootLayout() {
+		return !strings.HasPrefix(path, s.Metadata.Lib) && !strings.HasPrefix(path, "META-INF/") &&
+			!strings.HasPrefix(path, loader) && !s.Metadata.isAdditional(path)
+	}
+
 	return strings.HasPrefix(path, s.Metadata.Classes)
 }
 
 func (s SpringBoot) isDependencySlice(path string) bool {
-	return strings.HasPrefix(path, s.Metadata.Lib) && filepath.Ext(path) == ".jar" && !strings.Contains(path, "SNAPSHOT")
+	return (strings.HasPrefix(path, s.Metadata.Lib) || s.Metadata.isAdditional(path)) && filepath.Ext(path) == ".jar" &&
+		!strings.Contains(path, "SNAPSHOT")
 }
 
 func (s SpringBoot) isLaunchSlice(path string) bool {
-	return !strings.HasPrefix(path, s.Metadata.Classes) && !strings.HasPrefix(path, s.Metadata.Lib) && !strings.HasPrefix(path, "META-INF/")
+	if s.isRootLayout() {
+		return strings.HasPrefix(path, loader)
+	}
+
+	return !strings.HasPrefix(path, s.Metadata.Classes) && !strings.HasPrefix(path, s.Metadata.Lib) &&
+		!strings.HasPrefix(path, "META-INF/") && !s.Metadata.isAdditional(path)
+}
+
+// isRootLayout returns whether classes are packaged at the root of the application, beside the launcher, as in Spring
+// Boot 1.3 and earlier jars.
+func (s SpringBoot) isRootLayout() bool {
+	return s.Metadata.Classes == "" && s.Metadata.Lib != ""
 }
 
 func (s SpringBoot) isSnapshotSlice(path string) bool {
-	return strings.HasPrefix(path, s.Metadata.Lib) && filepath.Ext(path) == ".jar" && strings.Contains(path, "SNAPSHOT")
+	return (strings.HasPrefix(path, s.Metadata.Lib) || s.Metadata.isAdditional(path)) && filepath.Ext(path) == ".jar" &&
+		strings.Contains(path, "SNAPSHOT")
 }
 
 func (s SpringBoot) slices() (layers.Slices, error) {
@@ -186,6 +636,8 @@ func (s SpringBoot) slices() (layers.Slices, error) {
 
 		if s.isApplicationSlice(rel) {
 			app.Paths = append(app.Paths, rel)
+		} else if s.isExcludedSlice(rel) {
+			rem.Paths = append(rem.Paths, rel)
 		} else if s.isDependencySlice(rel) {
 			dep.Paths = append(dep.Paths, rel)
 		} else if s.isLaunchSlice(rel) {
@@ -207,7 +659,7 @@ func (s SpringBoot) slices() (layers.Slices, error) {
 // NewSpringBoot creates a new SpringBoot instance.  OK is true if the build plan contains a "jvm-application"
 // dependency and a "Spring-Boot-Version" manifest key.
 func NewSpringBoot(build build.Build) (SpringBoot, bool, error) {
-	md, ok, err := NewMetadata(build.Application, build.Logger)
+	md, pk, ok, err := newMetadata(build.Application, build.Logger)
 	if err != nil {
 		return SpringBoot{}, false, err
 	}
@@ -216,11 +668,159 @@ func NewSpringBoot(build build.Build) (SpringBoot, bool, error) {
 		return SpringBoot{}, false, nil
 	}
 
-	return SpringBoot{
-		md,
-		build.Application,
-		build.Layers.Layer(Dependency),
-		build.Layers,
-		build.Logger,
-	}, true, nil
+	p, err := process.NewConfiguration(build.Application)
+	if err != nil {
+		return SpringBoot{}, false, err
+	}
+
+	pr, err := NewProperties()
+	if err != nil {
+		return SpringBoot{}, false, err
+	}
+
+	db, err := NewDebug()
+	if err != nil {
+		return SpringBoot{}, false, err
+	}
+
+	d, err := NewDevTools()
+	if err != nil {
+		return SpringBoot{}, false, err
+	}
+
+	n, err := NewNativeImage()
+	if err != nil {
+		return SpringBoot{}, false, err
+	}
+
+	if n.Enabled && len(pk.Artifacts) > 0 {
+		return SpringBoot{}, false, fmt.Errorf(
+			"native image is not supported for Spring Boot artifacts that have not been exploded")
+	}
+
+	c, err := NewCDS()
+	if err != nil {
+		return SpringBoot{}, false, err
+	}
+
+	if c.Enabled && pk.isMultiple() {
+		return SpringBoot{}, false, fmt.Errorf("class data sharing is not supported for multiple Spring Boot artifacts, " +
+			"set $BP_SPRING_BOOT_ARTIFACT to select one")
+	}
+
+	s := SpringBoot{
+		Metadata:    md,
+		Properties:  pr,
+		application: build.Application,
+		cds:         c,
+		debug:       db,
+		devTools:    d,
+		packaging:   pk,
+		layer:       build.Layers.Layer(Dependency),
+		layers:      build.Layers,
+		logger:      build.Logger,
+		nativeImage: n,
+		processes:   p,
+		runner:      build.Runner,
+	}
+
+	if s.Metadata.Lib == "" {
+		r, err := thinRepository(build.Platform.Root)
+		if err != nil {
+			return SpringBoot{}, false, err
+		}
+
+		if t, ok, err := newThin(build.Application.Root, r); err != nil {
+			return SpringBoot{}, false, err
+		} else if ok {
+			s.thin = t
+			for _, a := range t.Artifacts {
+				s.Metadata.ClassPath = append(s.Metadata.ClassPath,
+					filepath.Join(build.Layers.Layer(ThinDependency).Root, filepath.FromSlash(a.File)))
+			}
+		}
+	}
+
+	if s.jakarta, err = NewJakartaReport(s.Metadata.Version); err != nil {
+		return SpringBoot{}, false, err
+	}
+
+	if s.jarDependencies, err = s.dependencies(); err != nil {
+		return SpringBoot{}, false, err
+	}
+
+	dd, err := NewDevelopmentDependencies()
+	if err != nil {
+		return SpringBoot{}, false, err
+	}
+	s.excludeDependencies(dd.excludedScopes(s.devTools)...)
+
+	if s.conflictPolicy, err = NewConflictPolicy(); err != nil {
+		return SpringBoot{}, false, err
+	}
+
+	s.support, _ = NewSupport(s.Metadata.Version, time.Now())
+
+	if s.supportPolicy, err = NewSupportPolicy(); err != nil {
+		return SpringBoot{}, false, err
+	}
+
+	if s.versionPolicy, err = NewVersionPolicy(); err != nil {
+		return SpringBoot{}, false, err
+	}
+
+	if s.nativeImage.Enabled {
+		files, err := readNativeImageFiles(s.application.Root, s.Metadata.Classes)
+		if err != nil {
+			return SpringBoot{}, false, err
+		}
+
+		for _, d := range s.classPathDependencies() {
+			files = append(files, d.nativeImage...)
+		}
+
+		if s.nativeImageConfiguration, err = newNativeImageConfiguration(files); err != nil {
+			return SpringBoot{}, false, err
+		}
+	}
+
+	if s.configurationFiles, err = newConfigurationFiles(build.Application.Root, s.Metadata.Classes); err != nil {
+		return SpringBoot{}, false, err
+	}
+
+	t, err := configuredWebApplicationType(build.Application.Root, s.Metadata.Classes, s.Properties.ApplicationJSON)
+	if err != nil {
+		return SpringBoot{}, false, err
+	}
+
+	// Without spring-boot the dependencies of the application are not known, e.g. for artifacts that have not been
+	// exploded, and the type of web application cannot be deduced.
+	if t != "" || s.hasDependency("spring-boot") {
+		pd, err := providedDependencies(build.Application.Root, s.Metadata.Lib)
+		if err != nil {
+			return SpringBoot{}, false, err
+		}
+
+		s.Metadata.WebApplicationType, s.Metadata.WebServer = webApplication(append(s.classPathDependencies(), pd...), t)
+	}
+
+	s.conflicts = newConflicts(s.classPathDependencies())
+	s.mismatches = versionMismatches(s.Metadata.Version, s.classPathDependencies())
+
+	s.javaAgents(NewPackagedAgents())
+
+	if s.modules, err = s.newModules(); err != nil {
+		return SpringBoot{}, false, err
+	}
+
+	if e, err := jreModulesEnabled(); err != nil {
+		return SpringBoot{}, false, err
+	} else if e {
+		cp := jreModulesClassPath(md.ClassPath, s.thin, dd.excludedScopes(s.devTools))
+		if s.jreModules.Modules, err = newJREModules(cp, s.logger); err != nil {
+			return SpringBoot{}, false, err
+		}
+	}
+
+	return s, true, nil
 }
@@ -17,12 +17,21 @@
 package springboot_test
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	bplogger "github.com/buildpacks/libbuildpack/v2/logger"
 	"github.com/cloudfoundry/libcfbuildpack/v2/buildpackplan"
 	"github.com/cloudfoundry/libcfbuildpack/v2/layers"
+	"github.com/cloudfoundry/libcfbuildpack/v2/logger"
+	"github.com/cloudfoundry/libcfbuildpack/v2/runner"
 	"github.com/cloudfoundry/libcfbuildpack/v2/test"
 	"github.com/cloudfoundry/spring-boot-cnb/springboot"
 	"github.com/onsi/gomega"
@@ -284,5 +293,1802 @@ Spring-Boot-Version: test-version`)
 				},
 			}))
 		})
+
+		when("development dependencies", func() {
+
+			it.Before(func() {
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "test-1.2.3.jar")
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "spring-boot-devtools-2.2.5.RELEASE.jar")
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "junit-jupiter-api-5.5.2.jar")
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+			})
+
+			it("removes development dependencies from classpath", func() {
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Metadata.ClassPath).To(gomega.Equal([]string{
+					filepath.Join(f.Build.Application.Root, "test-classes"),
+					filepath.Join(f.Build.Application.Root, "test-lib", "test-1.2.3.jar"),
+				}))
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				command := "java -cp $CLASSPATH $JAVA_OPTS test-start-class"
+				g.Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+					Slices: layers.Slices{
+						{},
+						{Paths: []string{"test-lib/test-1.2.3.jar"}},
+						{},
+						{},
+						{Paths: []string{
+							"META-INF/MANIFEST.MF",
+							"test-lib/junit-jupiter-api-5.5.2.jar",
+							"test-lib/spring-boot-devtools-2.2.5.RELEASE.jar",
+						}},
+					},
+					Processes: layers.Processes{
+						{Type: "spring-boot", Command: command},
+						{Type: "task", Command: command},
+						{Type: "web", Command: command},
+					},
+				}))
+
+				p, err := e.Plan()
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(p.Metadata["dependencies"]).To(gomega.Equal(springboot.JARDependencies{
+					{
+						Name:    "junit-jupiter-api",
+						Version: "5.5.2",
+						SHA256:  "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+						Scope:   springboot.TestScope,
+					},
+					{
+						Name:    "spring-boot-devtools",
+						Version: "2.2.5.RELEASE",
+						SHA256:  "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+						Scope:   springboot.DevelopmentScope,
+					},
+					{
+						Name:    "test",
+						Version: "1.2.3",
+						SHA256:  "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+					},
+				}))
+			})
+
+			it("keeps development dependencies when configured", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_KEEP_DEVELOPMENT_DEPENDENCIES", "true")()
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Metadata.ClassPath).To(gomega.Equal([]string{
+					filepath.Join(f.Build.Application.Root, "test-classes"),
+					filepath.Join(f.Build.Application.Root, "test-lib", "junit-jupiter-api-5.5.2.jar"),
+					filepath.Join(f.Build.Application.Root, "test-lib", "spring-boot-devtools-2.2.5.RELEASE.jar"),
+					filepath.Join(f.Build.Application.Root, "test-lib", "test-1.2.3.jar"),
+				}))
+			})
+
+			it("keeps devtools and adds dev process in development mode", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_DEVELOPMENT_MODE", "true")()
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Metadata.ClassPath).To(gomega.Equal([]string{
+					filepath.Join(f.Build.Application.Root, "test-classes"),
+					filepath.Join(f.Build.Application.Root, "test-lib", "spring-boot-devtools-2.2.5.RELEASE.jar"),
+					filepath.Join(f.Build.Application.Root, "test-lib", "test-1.2.3.jar"),
+				}))
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				command := "java -cp $CLASSPATH $JAVA_OPTS test-start-class"
+				g.Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+					Slices: layers.Slices{
+						{},
+						{Paths: []string{"test-lib/spring-boot-devtools-2.2.5.RELEASE.jar", "test-lib/test-1.2.3.jar"}},
+						{},
+						{},
+						{Paths: []string{"META-INF/MANIFEST.MF", "test-lib/junit-jupiter-api-5.5.2.jar"}},
+					},
+					Processes: layers.Processes{
+						{Type: "dev", Command: "java -cp $CLASSPATH $JAVA_OPTS -Dspring.devtools.restart.enabled=true test-start-class"},
+						{Type: "spring-boot", Command: command},
+						{Type: "task", Command: command},
+						{Type: "web", Command: command},
+					},
+				}))
+
+				p, err := e.Plan()
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(p.Metadata["devtools"]).To(gomega.Equal(map[string]interface{}{
+					"destination": f.Build.Application.Root,
+					"reload":      []string(nil),
+					"restart":     []string{"test-classes"},
+				}))
+			})
+
+			it("contributes remote secret to dev process type only", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_DEVELOPMENT_MODE", "true")()
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_DEVTOOLS_REMOTE_SECRET", "test-secret")()
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				layer := f.Build.Layers.Layer("spring-boot")
+				g.Expect(layer).To(test.HaveDefaultLaunchEnvironment("JAVA_DEVTOOLS_OPTS", "-Dspring.devtools.remote.secret=test-secret"))
+				g.Expect(filepath.Join(layer.Root, "env.launch", "SPRING_DEVTOOLS_REMOTE_SECRET.default")).NotTo(gomega.BeAnExistingFile())
+
+				command := "java -cp $CLASSPATH $JAVA_OPTS test-start-class"
+				g.Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+					Slices: layers.Slices{
+						{},
+						{Paths: []string{"test-lib/spring-boot-devtools-2.2.5.RELEASE.jar", "test-lib/test-1.2.3.jar"}},
+						{},
+						{},
+						{Paths: []string{"META-INF/MANIFEST.MF", "test-lib/junit-jupiter-api-5.5.2.jar"}},
+					},
+					Processes: layers.Processes{
+						{Type: "dev", Command: "java -cp $CLASSPATH $JAVA_OPTS -Dspring.devtools.restart.enabled=true $JAVA_DEVTOOLS_OPTS test-start-class"},
+						{Type: "spring-boot", Command: command},
+						{Type: "task", Command: command},
+						{Type: "web", Command: command},
+					},
+				}))
+			})
+
+			it("rejects invalid configuration", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_KEEP_DEVELOPMENT_DEPENDENCIES", "alpha")()
+
+				_, _, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(err).To(gomega.MatchError("invalid $BP_SPRING_BOOT_KEEP_DEVELOPMENT_DEPENDENCIES: alpha"))
+			})
+		})
+
+		it("contributes Spring configuration to launch", func() {
+			test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+				`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+			defer test.ReplaceEnv(t, "BP_SPRING_PROFILES_ACTIVE", "production")()
+			defer test.ReplaceEnv(t, "BP_SPRING_PROPERTY_SERVER_PORT", "8081")()
+
+			e, ok, err := springboot.NewSpringBoot(f.Build)
+			g.Expect(ok).To(gomega.BeTrue())
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			g.Expect(e.Contribute()).To(gomega.Succeed())
+
+			layer := f.Build.Layers.Layer("spring-boot")
+			g.Expect(layer).To(test.HaveDefaultLaunchEnvironment("SPRING_PROFILES_ACTIVE", "production"))
+			g.Expect(layer).To(test.HaveDefaultLaunchEnvironment("SPRING_APPLICATION_JSON", `{"server":{"port":"8081"}}`))
+		})
+
+		it("contributes Spring configuration containing format verbs", func() {
+			test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+				`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+			defer test.ReplaceEnv(t, "BP_SPRING_PROFILES_ACTIVE", "100%s")()
+			defer test.ReplaceEnv(t, "BP_SPRING_PROPERTY_LOGGING_PATTERN_CONSOLE", "%d{HH:mm} %msg%n")()
+
+			e, ok, err := springboot.NewSpringBoot(f.Build)
+			g.Expect(ok).To(gomega.BeTrue())
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			g.Expect(e.Contribute()).To(gomega.Succeed())
+
+			layer := f.Build.Layers.Layer("spring-boot")
+			g.Expect(layer).To(test.HaveDefaultLaunchEnvironment("SPRING_PROFILES_ACTIVE", "%s", "100%s"))
+			g.Expect(layer).To(test.HaveDefaultLaunchEnvironment("SPRING_APPLICATION_JSON", "%s",
+				`{"logging":{"pattern":{"console":"%d{HH:mm} %msg%n"}}}`))
+		})
+
+		when("conflicts", func() {
+
+			it.Before(func() {
+				for _, j := range []string{"guava-28.0-jre.jar", "guava-29.0-jre.jar", "test-shaded-1.0.0.jar"} {
+					test.CopyFile(t, filepath.Join("testdata", j), filepath.Join(f.Build.Application.Root, "test-lib", j))
+				}
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+			})
+
+			it("reports duplicate dependencies and split packages", func() {
+				var b bytes.Buffer
+				f.Build.Logger = logger.Logger{Logger: bplogger.NewLogger(nil, &b)}
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				g.Expect(b.String()).To(gomega.ContainSubstring("Found duplicate dependencies in Spring-Boot-Lib"))
+				g.Expect(b.String()).To(gomega.ContainSubstring("guava: 28.0-jre, 29.0-jre"))
+				g.Expect(b.String()).To(gomega.ContainSubstring("Found classes in more than one dependency in Spring-Boot-Lib"))
+				g.Expect(b.String()).To(gomega.ContainSubstring("guava-28.0-jre and test-shaded-1.0.0: 1 classes in com.google.common.base"))
+				g.Expect(b.String()).To(gomega.ContainSubstring("guava-29.0-jre and test-shaded-1.0.0: 1 classes in com.google.common.base"))
+			})
+
+			it("fails on duplicate dependencies and split packages when configured", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_FAIL_ON_CONFLICTS", "true")()
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.MatchError("found 1 duplicate dependencies and 2 split packages in test-lib"))
+			})
+		})
+
+		when("distribution", func() {
+
+			it.Before(func() {
+				test.CopyFile(t, filepath.Join("testdata", "test-boot-1.0.0.jar"),
+					filepath.Join(f.Build.Application.Root, "lib", "test-boot-1.0.0.jar"))
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "bin", "test-boot"), "#!/bin/sh")
+				g.Expect(os.Chmod(filepath.Join(f.Build.Application.Root, "bin", "test-boot"), 0755)).To(gomega.Succeed())
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "bin", "test-boot.bat"), "@echo off")
+			})
+
+			it("reuses start script", func() {
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Metadata.ClassPath).To(gomega.Equal([]string{
+					filepath.Join(f.Build.Application.Root, "lib", "test-boot-1.0.0.jar"),
+				}))
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				command := filepath.Join(f.Build.Application.Root, "bin", "test-boot")
+				g.Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+					Slices: layers.Slices{
+						{Paths: []string{"bin/test-boot", "bin/test-boot.bat"}},
+						{},
+						{},
+						{Paths: []string{"lib/test-boot-1.0.0.jar"}},
+						{},
+					},
+					Processes: layers.Processes{
+						{Type: "spring-boot", Command: command},
+						{Type: "task", Command: command},
+						{Type: "web", Command: command},
+					},
+				}))
+			})
+
+			it("launches boot jar without start script", func() {
+				g.Expect(os.Remove(filepath.Join(f.Build.Application.Root, "bin", "test-boot"))).To(gomega.Succeed())
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				command := "java -cp $CLASSPATH $JAVA_OPTS org.springframework.boot.loader.JarLauncher"
+				g.Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+					Slices: layers.Slices{
+						{Paths: []string{"bin/test-boot.bat"}},
+						{},
+						{},
+						{Paths: []string{"lib/test-boot-1.0.0.jar"}},
+						{},
+					},
+					Processes: layers.Processes{
+						{Type: "spring-boot", Command: command},
+						{Type: "task", Command: command},
+						{Type: "web", Command: command},
+					},
+				}))
+			})
+
+			it("rejects native image", func() {
+				path, err := filepath.Abs(filepath.Join("testdata", "bin"))
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				defer test.ReplaceEnv(t, "PATH", path)()
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_NATIVE_IMAGE", "true")()
+
+				_, _, err = springboot.NewSpringBoot(f.Build)
+				g.Expect(err).To(gomega.MatchError(gomega.HavePrefix("native image is not supported for Spring Boot artifacts that have not been exploded")))
+			})
+		})
+
+		when("multiple artifacts", func() {
+
+			it.Before(func() {
+				for _, j := range []string{"test-boot-1.0.0.jar", "test-worker-2.0.0.jar"} {
+					test.CopyFile(t, filepath.Join("testdata", j), filepath.Join(f.Build.Application.Root, j))
+				}
+			})
+
+			it("contributes process type for each artifact", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_DEFAULT_PROCESS", "test-boot")()
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				boot := fmt.Sprintf("java -cp %s $JAVA_OPTS org.springframework.boot.loader.JarLauncher",
+					filepath.Join(f.Build.Application.Root, "test-boot-1.0.0.jar"))
+				worker := fmt.Sprintf("java -cp %s $JAVA_OPTS org.springframework.boot.loader.JarLauncher",
+					filepath.Join(f.Build.Application.Root, "test-worker-2.0.0.jar"))
+				g.Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+					Slices: layers.Slices{
+						{},
+						{},
+						{},
+						{Paths: []string{"test-boot-1.0.0.jar", "test-worker-2.0.0.jar"}},
+						{},
+					},
+					Processes: layers.Processes{
+						{Type: "test-boot", Command: boot},
+						{Type: "test-worker", Command: worker},
+						{Type: "web", Command: boot},
+					},
+				}))
+
+				p, err := e.Plan()
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(p.Metadata["artifacts"]).To(gomega.Equal([]map[string]interface{}{
+					{"name": "test-boot", "path": "test-boot-1.0.0.jar", "start-class": "test.Application", "version": "2.7.18"},
+					{"name": "test-worker", "path": "test-worker-2.0.0.jar", "start-class": "test.Worker", "version": "2.7.18"},
+				}))
+			})
+
+			it("selects artifact", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_ARTIFACT", "test-worker-*.jar")()
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Metadata.StartClass).To(gomega.Equal("test.Worker"))
+				g.Expect(e.Metadata.ClassPath).To(gomega.Equal([]string{
+					filepath.Join(f.Build.Application.Root, "test-worker-2.0.0.jar"),
+				}))
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				command := "java -cp $CLASSPATH $JAVA_OPTS org.springframework.boot.loader.JarLauncher"
+				g.Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+					Slices: layers.Slices{
+						{},
+						{Paths: []string{"test-boot-1.0.0.jar"}},
+						{},
+						{Paths: []string{"test-worker-2.0.0.jar"}},
+						{},
+					},
+					Processes: layers.Processes{
+						{Type: "spring-boot", Command: command},
+						{Type: "task", Command: command},
+						{Type: "web", Command: command},
+					},
+				}))
+			})
+
+			it("rejects ambiguous selection", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_ARTIFACT", "test-*.jar")()
+
+				_, _, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(err).To(gomega.MatchError("$BP_SPRING_BOOT_ARTIFACT test-*.jar matches multiple Spring Boot artifacts: test-boot-1.0.0.jar, test-worker-2.0.0.jar"))
+			})
+
+			it("rejects selection without match", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_ARTIFACT", "api*.jar")()
+
+				_, _, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(err).To(gomega.MatchError("no Spring Boot artifact in the application root matches $BP_SPRING_BOOT_ARTIFACT api*.jar"))
+			})
+
+			it("rejects artifacts with the same process type", func() {
+				test.CopyFile(t, filepath.Join("testdata", "test-worker-2.0.0.jar"), filepath.Join(f.Build.Application.Root, "test-worker.jar"))
+
+				_, _, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(err).To(gomega.MatchError(gomega.HavePrefix("Spring Boot artifacts test-worker-2.0.0.jar and test-worker.jar both map to process type test-worker")))
+			})
+
+			it("rejects artifacts that map to invalid process types", func() {
+				test.CopyFile(t, filepath.Join("testdata", "test-worker-2.0.0.jar"), filepath.Join(f.Build.Application.Root, "test.api-1.0.0.jar"))
+
+				_, _, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(err).To(gomega.MatchError(gomega.HavePrefix(`Spring Boot artifact test.api-1.0.0.jar maps to invalid process type "test.api"`)))
+			})
+		})
+
+		when("module path", func() {
+
+			it.Before(func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+				test.CopyFile(t, filepath.Join("testdata", "module-info.class"),
+					filepath.Join(f.Build.Application.Root, "test-classes", "module-info.class"))
+				for _, j := range []string{"test-automatic-1.0.0.jar", "test-module-1.0.0.jar"} {
+					test.CopyFile(t, filepath.Join("testdata", j), filepath.Join(f.Build.Application.Root, "test-lib", j))
+				}
+			})
+
+			it("launches on module path", func() {
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				command := fmt.Sprintf("java --module-path %s --add-modules ALL-MODULE-PATH $JAVA_OPTS -m test.application/test-start-class",
+					strings.Join([]string{
+						filepath.Join(f.Build.Application.Root, "test-classes"),
+						filepath.Join(f.Build.Application.Root, "test-lib", "test-automatic-1.0.0.jar"),
+						filepath.Join(f.Build.Application.Root, "test-lib", "test-module-1.0.0.jar"),
+					}, string(filepath.ListSeparator)))
+				g.Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+					Slices: layers.Slices{
+						{},
+						{Paths: []string{"test-lib/test-automatic-1.0.0.jar", "test-lib/test-module-1.0.0.jar"}},
+						{},
+						{Paths: []string{"test-classes/module-info.class"}},
+						{Paths: []string{"META-INF/MANIFEST.MF"}},
+					},
+					Processes: layers.Processes{
+						{Type: "spring-boot", Command: command},
+						{Type: "task", Command: command},
+						{Type: "web", Command: command},
+					},
+				}))
+
+				p, err := e.Plan()
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				var m []string
+				for _, d := range p.Metadata["dependencies"].(springboot.JARDependencies) {
+					m = append(m, d.Module)
+				}
+				g.Expect(m).To(gomega.ConsistOf("test.automatic", "test.module"))
+			})
+
+			it("falls back to classpath when modules contain the same package", func() {
+				test.CopyFile(t, filepath.Join("testdata", "test-automatic-split-1.0.0.jar"),
+					filepath.Join(f.Build.Application.Root, "test-lib", "test-automatic-split-1.0.0.jar"))
+
+				var b bytes.Buffer
+				f.Build.Logger = logger.Logger{Logger: bplogger.NewLogger(nil, &b)}
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				command := "java -cp $CLASSPATH $JAVA_OPTS test-start-class"
+				g.Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+					Slices: layers.Slices{
+						{},
+						{Paths: []string{"test-lib/test-automatic-1.0.0.jar", "test-lib/test-automatic-split-1.0.0.jar", "test-lib/test-module-1.0.0.jar"}},
+						{},
+						{Paths: []string{"test-classes/module-info.class"}},
+						{Paths: []string{"META-INF/MANIFEST.MF"}},
+					},
+					Processes: layers.Processes{
+						{Type: "spring-boot", Command: command},
+						{Type: "task", Command: command},
+						{Type: "web", Command: command},
+					},
+				}))
+				g.Expect(b.String()).To(gomega.ContainSubstring("test-automatic-1.0.0.jar and test-automatic-split-1.0.0.jar both contain packages test.automatic"))
+			})
+
+			it("falls back to classpath when a dependency is not a module", func() {
+				test.CopyFile(t, filepath.Join("testdata", "test-shaded-1.0.0.jar"),
+					filepath.Join(f.Build.Application.Root, "test-lib", "test-shaded-1.0.0.jar"))
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				command := "java -cp $CLASSPATH $JAVA_OPTS test-start-class"
+				g.Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+					Slices: layers.Slices{
+						{},
+						{Paths: []string{"test-lib/test-automatic-1.0.0.jar", "test-lib/test-module-1.0.0.jar", "test-lib/test-shaded-1.0.0.jar"}},
+						{},
+						{Paths: []string{"test-classes/module-info.class"}},
+						{Paths: []string{"META-INF/MANIFEST.MF"}},
+					},
+					Processes: layers.Processes{
+						{Type: "spring-boot", Command: command},
+						{Type: "task", Command: command},
+						{Type: "web", Command: command},
+					},
+				}))
+			})
+		})
+
+		when("thin launcher", func() {
+
+			var repository string
+
+			it.Before(func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "thin.properties"),
+					`
+computed=true
+dependencies.guava=com.google.guava:guava:28.0-jre`)
+
+				repository = filepath.Join(test.ScratchDir(t, "bindings"), "repository")
+				jar := filepath.Join(repository, "com", "google", "guava", "guava", "28.0-jre", "guava-28.0-jre.jar")
+				test.CopyFile(t, filepath.Join("testdata", "guava-28.0-jre.jar"), jar)
+
+				b, err := ioutil.ReadFile(jar)
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				sum := sha256.Sum256(b)
+				test.WriteFile(t, jar+".sha256", hex.EncodeToString(sum[:]))
+			})
+
+			it("resolves dependencies from repository", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_THIN_REPOSITORY", repository)()
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				layer := f.Build.Layers.Layer("thin-dependencies")
+				g.Expect(e.Metadata.ClassPath).To(gomega.ContainElement(filepath.Join(layer.Root, "com.google.guava", "guava-28.0-jre.jar")))
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+				g.Expect(layer).To(test.HaveLayerMetadata(false, true, true))
+				g.Expect(filepath.Join(layer.Root, "com.google.guava", "guava-28.0-jre.jar")).To(gomega.BeARegularFile())
+			})
+
+			it("keeps artifacts with the same artifactId in different groups", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_THIN_REPOSITORY", repository)()
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "thin.properties"),
+					`
+computed=true
+dependencies.guava=com.google.guava:guava:28.0-jre
+dependencies.fork=com.example:guava:28.0-jre`)
+
+				jar := filepath.Join(repository, "com", "example", "guava", "28.0-jre", "guava-28.0-jre.jar")
+				test.CopyFile(t, filepath.Join("testdata", "guava-29.0-jre.jar"), jar)
+
+				b, err := ioutil.ReadFile(jar)
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				sum := sha256.Sum256(b)
+				test.WriteFile(t, jar+".sha256", hex.EncodeToString(sum[:]))
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				layer := f.Build.Layers.Layer("thin-dependencies")
+				g.Expect(e.Metadata.ClassPath).To(gomega.ContainElement(filepath.Join(layer.Root, "com.google.guava", "guava-28.0-jre.jar")))
+				g.Expect(e.Metadata.ClassPath).To(gomega.ContainElement(filepath.Join(layer.Root, "com.example", "guava-28.0-jre.jar")))
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+				b, err = ioutil.ReadFile(filepath.Join(layer.Root, "com.example", "guava-28.0-jre.jar"))
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(sha256.Sum256(b)).To(gomega.Equal(sum))
+				g.Expect(filepath.Join(layer.Root, "com.google.guava", "guava-28.0-jre.jar")).To(gomega.BeARegularFile())
+			})
+
+			it("resolves repository from binding", func() {
+				defer test.ReplaceEnv(t, "SERVICE_BINDING_ROOT", filepath.Dir(repository))()
+				test.WriteFile(t, filepath.Join(repository, "type"), "maven-repository")
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Metadata.ClassPath).To(gomega.ContainElement(
+					filepath.Join(f.Build.Layers.Layer("thin-dependencies").Root, "com.google.guava", "guava-28.0-jre.jar")))
+			})
+
+			it("rejects checksum mismatch", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_THIN_REPOSITORY", repository)()
+				test.WriteFile(t, filepath.Join(repository, "com", "google", "guava", "guava", "28.0-jre", "guava-28.0-jre.jar.sha512"), "0000")
+
+				_, _, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(err).To(gomega.MatchError(gomega.HavePrefix("unable to verify com.google.guava:guava:28.0-jre: checksum")))
+			})
+
+			it("rejects missing repository", func() {
+				_, _, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(err).To(gomega.MatchError(gomega.HavePrefix("a Maven repository is required to resolve thin launcher dependencies")))
+			})
+
+			it("ignores thin.properties when Spring-Boot-Lib is present", func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+				g.Expect(f.Build.Layers.Layer("thin-dependencies").Root).NotTo(gomega.BeADirectory())
+			})
+
+			it("rejects dependencies that are not computed", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_THIN_REPOSITORY", repository)()
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "thin.properties"), "dependencies.guava=com.google.guava:guava:")
+
+				_, _, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(err).To(gomega.MatchError(gomega.HavePrefix("META-INF/thin.properties must contain the computed dependencies")))
+			})
+		})
+
+		when("web application", func() {
+
+			it.Before(func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "spring-boot-2.7.18.jar")
+			})
+
+			it("deduces servlet web application", func() {
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "spring-web-5.3.31.jar")
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "spring-webmvc-5.3.31.jar")
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "tomcat-embed-core-9.0.83.jar")
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Metadata.WebApplicationType).To(gomega.Equal("servlet"))
+				g.Expect(e.Metadata.WebServer).To(gomega.Equal("tomcat"))
+
+				p, err := e.Plan()
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(p.Metadata).To(gomega.HaveKeyWithValue("web-application-type", "servlet"))
+				g.Expect(p.Metadata).To(gomega.HaveKeyWithValue("web-server", "tomcat"))
+			})
+
+			it("deduces servlet web application of executable war", func() {
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "spring-web-5.3.31.jar")
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "spring-webmvc-5.3.31.jar")
+				test.TouchFile(t, f.Build.Application.Root, "test-lib-provided", "tomcat-embed-core-9.0.83.jar")
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Metadata.WebApplicationType).To(gomega.Equal("servlet"))
+				g.Expect(e.Metadata.WebServer).To(gomega.Equal("tomcat"))
+			})
+
+			it("deduces reactive web application", func() {
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "spring-web-5.3.31.jar")
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "spring-webflux-5.3.31.jar")
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "reactor-netty-http-1.0.39.jar")
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Metadata.WebApplicationType).To(gomega.Equal("reactive"))
+				g.Expect(e.Metadata.WebServer).To(gomega.Equal("reactor-netty"))
+			})
+
+			it("prefers servlet web application when Spring MVC is present", func() {
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "jetty-server-9.4.53.v20231009.jar")
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "javax.servlet-api-4.0.1.jar")
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "spring-web-5.3.31.jar")
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "spring-webflux-5.3.31.jar")
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "spring-webmvc-5.3.31.jar")
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "reactor-netty-http-1.0.39.jar")
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Metadata.WebApplicationType).To(gomega.Equal("servlet"))
+				g.Expect(e.Metadata.WebServer).To(gomega.Equal("jetty"))
+			})
+
+			it("does not contribute web process type for non-web application", func() {
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Metadata.WebApplicationType).To(gomega.Equal("none"))
+				g.Expect(e.Metadata.WebServer).To(gomega.Equal("none"))
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				command := "java -cp $CLASSPATH $JAVA_OPTS test-start-class"
+				g.Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+					Slices: layers.Slices{
+						{},
+						{Paths: []string{"test-lib/spring-boot-2.7.18.jar"}},
+						{},
+						{},
+						{Paths: []string{"META-INF/MANIFEST.MF"}},
+					},
+					Processes: layers.Processes{
+						{Type: "spring-boot", Command: command},
+						{Type: "task", Command: command},
+					},
+				}))
+			})
+
+			it("contributes web process type for non-web application with default process type", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_DEFAULT_PROCESS", "spring-boot")()
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				command := "java -cp $CLASSPATH $JAVA_OPTS test-start-class"
+				g.Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+					Slices: layers.Slices{
+						{},
+						{Paths: []string{"test-lib/spring-boot-2.7.18.jar"}},
+						{},
+						{},
+						{Paths: []string{"META-INF/MANIFEST.MF"}},
+					},
+					Processes: layers.Processes{
+						{Type: "spring-boot", Command: command},
+						{Type: "task", Command: command},
+						{Type: "web", Command: command},
+					},
+				}))
+			})
+
+			it("reads web application type from configuration", func() {
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "spring-web-5.3.31.jar")
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "spring-webmvc-5.3.31.jar")
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "tomcat-embed-core-9.0.83.jar")
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "test-classes", "application.yml"), `
+spring:
+  main:
+    web-application-type: none
+---
+spring:
+  config:
+    activate:
+      on-profile: web
+  main:
+    web-application-type: servlet
+`)
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Metadata.WebApplicationType).To(gomega.Equal("none"))
+				g.Expect(e.Metadata.WebServer).To(gomega.Equal("none"))
+			})
+
+			it("reads web application type from build-time configuration", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_PROPERTY_SPRING_MAIN_WEB__APPLICATION__TYPE", "REACTIVE")()
+				test.TouchFile(t, f.Build.Application.Root, "test-lib", "reactor-netty-1.0.39.jar")
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "test-classes", "application.properties"),
+					"spring.main.web-application-type=none")
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Metadata.WebApplicationType).To(gomega.Equal("reactive"))
+				g.Expect(e.Metadata.WebServer).To(gomega.Equal("reactor-netty"))
+			})
+
+			it("does not deduce web application type when dependencies are not known", func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: other-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Metadata.WebApplicationType).To(gomega.BeEmpty())
+
+				p, err := e.Plan()
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(p.Metadata).NotTo(gomega.HaveKey("web-application-type"))
+				g.Expect(p.Metadata).NotTo(gomega.HaveKey("web-server"))
+			})
+
+			it("rejects invalid web application type", func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "test-classes", "application.properties"),
+					"spring.main.web-application-type=test-value")
+
+				_, _, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(err).To(gomega.MatchError(
+					fmt.Sprintf("invalid spring.main.web-application-type in %s: test-value", filepath.Join("test-classes", "application.properties"))))
+			})
+		})
+
+		when("configuration files", func() {
+
+			it.Before(func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+			})
+
+			it("contributes profiles of profile-specific files", func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "test-classes", "application.properties"),
+					"server.port=${PORT:8080}")
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "test-classes", "application.yml"), `
+spring:
+  application:
+    name: test
+---
+spring:
+  config:
+    activate:
+      on-profile: local
+`)
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "test-classes", "application-cloud.yml"), "server:\n  port: 8081")
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "test-classes", "config", "bootstrap-dev.properties"), "alpha=bravo")
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "test-classes", "logback-cloud.xml"), "<configuration/>")
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "test-classes", "templates", "application-nested.yml"), "alpha: bravo: charlie")
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				p, err := e.Plan()
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(p.Metadata["profiles"]).To(gomega.Equal([]string{"cloud", "dev"}))
+			})
+
+			it("does not contribute profiles without profile-specific files", func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "test-classes", "application.properties"), "alpha=bravo")
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				p, err := e.Plan()
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(p.Metadata).NotTo(gomega.HaveKey("profiles"))
+			})
+
+			it("rejects YAML syntax errors", func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "test-classes", "application.yml"), `spring:
+  application:
+    name: test
+---
+server:
+  port: 8080
+  address: 127.0.0.1: 8080
+`)
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "test-classes", "bootstrap.yml"), "alpha:\n\tbravo: charlie")
+
+				_, _, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(err).To(gomega.MatchError(fmt.Sprintf("found 2 invalid Spring configuration files:\n  %s\n  %s",
+					filepath.Join("test-classes", "application.yml")+":7: mapping values are not allowed in this context",
+					filepath.Join("test-classes", "bootstrap.yml")+":2: found character that cannot start any token")))
+			})
+
+			it("rejects properties syntax errors", func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "test-classes", "application-cloud.properties"),
+					"alpha=bravo\ncharlie=\\u00")
+
+				_, _, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(err).To(gomega.MatchError(gomega.HavePrefix(fmt.Sprintf("found 1 invalid Spring configuration files:\n  %s:2: ",
+					filepath.Join("test-classes", "application-cloud.properties")))))
+			})
+		})
+
+		when("JRE modules", func() {
+
+			it.Before(func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+				test.CopyFile(t, filepath.Join("testdata", "Application.class"),
+					filepath.Join(f.Build.Application.Root, "test-classes", "test", "Application.class"))
+				test.CopyFile(t, filepath.Join("testdata", "test-xa-1.0.0.jar"),
+					filepath.Join(f.Build.Application.Root, "test-lib", "test-xa-1.0.0.jar"))
+			})
+
+			it("does not contribute modules by default", func() {
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+				g.Expect(filepath.Join(f.Build.Layers.Layer("jre-modules").Root, "modules.txt")).NotTo(gomega.BeAnExistingFile())
+
+				p, err := e.Plan()
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(p.Metadata).NotTo(gomega.HaveKey("jre-modules"))
+			})
+
+			it("contributes modules referenced by classes and dependencies", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_JRE_MODULES", "true")()
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				layer := f.Build.Layers.Layer("jre-modules")
+				g.Expect(layer).To(test.HaveLayerMetadata(false, false, true))
+				g.Expect(filepath.Join(layer.Root, "modules.txt")).To(test.HaveContent(
+					"java.base\njava.compiler\njava.logging\njava.sql\njava.transaction.xa\njdk.crypto.ec\n"))
+
+				p, err := e.Plan()
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(p.Metadata["jre-modules"]).To(gomega.Equal(
+					[]string{"java.base", "java.compiler", "java.logging", "java.sql", "java.transaction.xa", "jdk.crypto.ec"}))
+			})
+
+			it("returns modules for the build plan", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_JRE_MODULES", "true")()
+
+				m, ok, err := springboot.NewJREModules(f.Build.Application, f.Build.Platform.Root, f.Build.Logger)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(m).To(gomega.Equal([]string{"java.base", "java.compiler", "java.logging", "java.sql", "java.transaction.xa", "jdk.crypto.ec"}))
+			})
+
+			it("adds modules from $BP_SPRING_BOOT_JRE_MODULES_EXTRA", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_JRE_MODULES", "true")()
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_JRE_MODULES_EXTRA", "jdk.localedata, jdk.zipfs")()
+
+				m, ok, err := springboot.NewJREModules(f.Build.Application, f.Build.Platform.Root, f.Build.Logger)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(m).To(gomega.ContainElement("jdk.localedata"))
+				g.Expect(m).To(gomega.ContainElement("jdk.zipfs"))
+			})
+
+			it("returns the modules of kept development dependencies for the build plan", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_JRE_MODULES", "true")()
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_KEEP_DEVELOPMENT_DEPENDENCIES", "true")()
+				g.Expect(os.Rename(filepath.Join(f.Build.Application.Root, "test-lib", "test-xa-1.0.0.jar"),
+					filepath.Join(f.Build.Application.Root, "test-lib", "junit-4.12.jar"))).To(gomega.Succeed())
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				p, err := e.Plan()
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(p.Metadata["jre-modules"]).To(gomega.ContainElement("java.transaction.xa"))
+
+				m, ok, err := springboot.NewJREModules(f.Build.Application, f.Build.Platform.Root, f.Build.Logger)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(m).To(gomega.Equal(p.Metadata["jre-modules"]))
+			})
+
+			it("returns the modules of thin launcher dependencies for the build plan", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_JRE_MODULES", "true")()
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "thin.properties"),
+					`
+computed=true
+dependencies.test-xa=com.example:test-xa:1.0.0`)
+
+				repository := filepath.Join(test.ScratchDir(t, "bindings"), "repository")
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_THIN_REPOSITORY", repository)()
+				jar := filepath.Join(repository, "com", "example", "test-xa", "1.0.0", "test-xa-1.0.0.jar")
+				test.CopyFile(t, filepath.Join("testdata", "test-xa-1.0.0.jar"), jar)
+
+				b, err := ioutil.ReadFile(jar)
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				sum := sha256.Sum256(b)
+				test.WriteFile(t, jar+".sha256", hex.EncodeToString(sum[:]))
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				p, err := e.Plan()
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(p.Metadata["jre-modules"]).To(gomega.ContainElement("java.transaction.xa"))
+
+				m, ok, err := springboot.NewJREModules(f.Build.Application, f.Build.Platform.Root, f.Build.Logger)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(m).To(gomega.Equal(p.Metadata["jre-modules"]))
+			})
+
+			it("does not return modules for the build plan when thin launcher dependencies cannot be resolved", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_JRE_MODULES", "true")()
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "thin.properties"),
+					`
+computed=true
+dependencies.guava=com.google.guava:guava:28.0-jre`)
+
+				_, ok, err := springboot.NewJREModules(f.Build.Application, f.Build.Platform.Root, f.Build.Logger)
+				g.Expect(ok).To(gomega.BeFalse())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+			})
+
+			it("rejects invalid $BP_SPRING_BOOT_JRE_MODULES", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_JRE_MODULES", "test-value")()
+
+				_, _, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(err).To(gomega.MatchError("invalid $BP_SPRING_BOOT_JRE_MODULES: test-value"))
+			})
+		})
+
+		when("class data sharing", func() {
+
+			it.Before(func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: 3.2.0`)
+			})
+
+			it("generates class data sharing archive", func() {
+				path, err := filepath.Abs(filepath.Join("testdata", "bin"))
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				defer test.ReplaceEnv(t, "PATH", fmt.Sprintf("%s%c%s", path, filepath.ListSeparator, os.Getenv("PATH")))()
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_CDS", "true")()
+				f.Build.Runner = runner.CommandRunner{}
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				layer := f.Build.Layers.Layer("class-data-sharing")
+				archive := filepath.Join(layer.Root, "application.jsa")
+				g.Expect(layer).To(test.HaveLayerMetadata(false, false, true))
+				g.Expect(archive).To(test.HaveContent("archive"))
+				g.Expect(layer).To(test.HaveAppendLaunchEnvironment("JAVA_OPTS", " -XX:SharedArchiveFile=%s", archive))
+			})
+
+			it("trains with classpath and start class", func() {
+				path, err := filepath.Abs(filepath.Join("testdata", "bin"))
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				defer test.ReplaceEnv(t, "PATH", path)()
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_CDS", "true")()
+				f.Runner.Outputs = []string{"     ccstr ArchiveClassesAtExit = {product} {default}", `openjdk version "17.0.9"`}
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				g.Expect(f.Runner.Commands).To(gomega.Equal([]test.Command{{
+					Bin:  filepath.Join(path, "java"),
+					Dir:  f.Build.Application.Root,
+					Args: []string{"-XX:+PrintFlagsFinal", "-version"},
+				}, {
+					Bin:  filepath.Join(path, "java"),
+					Dir:  f.Build.Application.Root,
+					Args: []string{"-version"},
+				}, {
+					Bin: filepath.Join(path, "java"),
+					Dir: f.Build.Application.Root,
+					Args: []string{
+						fmt.Sprintf("-XX:ArchiveClassesAtExit=%s", filepath.Join(f.Build.Layers.Layer("class-data-sharing").Root, "application.jsa")),
+						"-Dspring.context.exit=onRefresh",
+						"-cp", filepath.Join(f.Build.Application.Root, "test-classes"),
+						"test-start-class",
+					},
+				}}))
+			})
+
+			it("regenerates class data sharing archive when the JVM changes", func() {
+				path, err := filepath.Abs(filepath.Join("testdata", "bin"))
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				defer test.ReplaceEnv(t, "PATH", path)()
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_CDS", "true")()
+
+				flags := "     ccstr ArchiveClassesAtExit = {product} {default}"
+				f.Runner.Outputs = []string{
+					flags, `openjdk version "17.0.9"`,
+					flags, `openjdk version "17.0.9"`,
+					flags, `openjdk version "17.0.10"`,
+				}
+
+				for i := 0; i < 3; i++ {
+					e, ok, err := springboot.NewSpringBoot(f.Build)
+					g.Expect(ok).To(gomega.BeTrue())
+					g.Expect(err).NotTo(gomega.HaveOccurred())
+
+					g.Expect(e.Contribute()).To(gomega.Succeed())
+				}
+
+				var training int
+				for _, c := range f.Runner.Commands {
+					if c.Args[0] != "-XX:+PrintFlagsFinal" && c.Args[0] != "-version" {
+						training++
+					}
+				}
+				g.Expect(training).To(gomega.Equal(2))
+			})
+
+			it("skips class data sharing archive before Spring Boot 3.2", func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: 3.1.12`)
+
+				path, err := filepath.Abs(filepath.Join("testdata", "bin"))
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				defer test.ReplaceEnv(t, "PATH", path)()
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_CDS", "true")()
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				g.Expect(f.Runner.Commands).To(gomega.BeEmpty())
+				g.Expect(f.Build.Layers.Layer("class-data-sharing").Root).NotTo(gomega.BeADirectory())
+			})
+
+			it("skips class data sharing archive when java does not support ArchiveClassesAtExit", func() {
+				path, err := filepath.Abs(filepath.Join("testdata", "bin"))
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				defer test.ReplaceEnv(t, "PATH", path)()
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_CDS", "true")()
+				f.Runner.Outputs = []string{"     bool UseSharedSpaces = true {product} {default}"}
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				g.Expect(f.Runner.Commands).To(gomega.HaveLen(1))
+				g.Expect(f.Build.Layers.Layer("class-data-sharing").Root).NotTo(gomega.BeADirectory())
+			})
+
+			it("skips class data sharing archive without java", func() {
+				defer test.ReplaceEnv(t, "PATH", "")()
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_CDS", "true")()
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				g.Expect(f.Runner.Commands).To(gomega.BeEmpty())
+				g.Expect(f.Build.Layers.Layer("class-data-sharing").Root).NotTo(gomega.BeADirectory())
+			})
+		})
+
+		when("native image", func() {
+
+			it.Before(func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+				test.TouchFile(t, f.Build.Application.Root, "test-classes", "test.class")
+			})
+
+			it("compiles native executable", func() {
+				path, err := filepath.Abs(filepath.Join("testdata", "bin"))
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				defer test.ReplaceEnv(t, "PATH", fmt.Sprintf("%s%c%s", path, filepath.ListSeparator, os.Getenv("PATH")))()
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_NATIVE_IMAGE", "true")()
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_NATIVE_IMAGE_ARGS", "--verbose")()
+				f.Build.Runner = runner.CommandRunner{}
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				layer := f.Build.Layers.Layer("native-image")
+				executable := filepath.Join(layer.Root, "application")
+				g.Expect(layer).To(test.HaveLayerMetadata(false, false, true))
+				g.Expect(executable).To(test.HaveContent("native"))
+
+				g.Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+					Slices: layers.Slices{{}, {}, {}, {Paths: []string{"test-classes/test.class"}}, {Paths: []string{"META-INF/MANIFEST.MF"}}},
+					Processes: layers.Processes{
+						{Type: "spring-boot", Command: executable},
+						{Type: "task", Command: executable},
+						{Type: "web", Command: executable},
+					},
+				}))
+			})
+
+			it("passes classpath and start class to native-image", func() {
+				path, err := filepath.Abs(filepath.Join("testdata", "bin"))
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				defer test.ReplaceEnv(t, "PATH", path)()
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_NATIVE_IMAGE", "true")()
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				g.Expect(f.Runner.Commands).To(gomega.Equal([]test.Command{{
+					Bin: filepath.Join(path, "native-image"),
+					Dir: f.Build.Application.Root,
+					Args: []string{
+						"--no-fallback",
+						"-cp", filepath.Join(f.Build.Application.Root, "test-classes"),
+						"-o", filepath.Join(f.Build.Layers.Layer("native-image").Root, "application"),
+						"test-start-class",
+					},
+				}}))
+			})
+
+			it("merges native-image configuration", func() {
+				path, err := filepath.Abs(filepath.Join("testdata", "bin"))
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				defer test.ReplaceEnv(t, "PATH", path)()
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_NATIVE_IMAGE", "true")()
+
+				for _, j := range []string{"test-native-a-1.0.0.jar", "test-native-b-1.0.0.jar"} {
+					test.CopyFile(t, filepath.Join("testdata", j), filepath.Join(f.Build.Application.Root, "test-lib", j))
+				}
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "test-classes", "META-INF", "native-image", "resource-config.json"),
+					`{"resources":{"includes":[{"pattern":"application.properties"}]}}`)
+
+				var b bytes.Buffer
+				f.Build.Logger = logger.Logger{Logger: bplogger.NewLogger(nil, &b)}
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				g.Expect(b.String()).To(gomega.ContainSubstring("Found native-image configuration that differs between sources"))
+				g.Expect(b.String()).To(gomega.ContainSubstring("reflect-config.json[test.Shared] in test-native-b-1.0.0.jar!/META-INF/native-image/test/b/reflect-config.json differs from test-native-a-1.0.0.jar!/META-INF/native-image/test/a/reflect-config.json"))
+
+				layer := f.Build.Layers.Layer("native-image-configuration")
+				g.Expect(ioutil.ReadFile(filepath.Join(layer.Root, "reflect-config.json"))).To(gomega.MatchJSON(`[
+  {"name": "test.A", "allDeclaredFields": true},
+  {"name": "test.Shared", "allPublicMethods": true},
+  {"name": "test.B"}
+]`))
+				g.Expect(ioutil.ReadFile(filepath.Join(layer.Root, "resource-config.json"))).
+					To(gomega.MatchJSON(`{"resources":{"includes":[{"pattern":"application.properties"}]}}`))
+
+				g.Expect(f.Runner.Commands[0].Args).To(gomega.ContainElement(fmt.Sprintf("-H:ConfigurationFileDirectories=%s", layer.Root)))
+			})
+
+			it("rejects malformed native-image configuration", func() {
+				path, err := filepath.Abs(filepath.Join("testdata", "bin"))
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				defer test.ReplaceEnv(t, "PATH", path)()
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_NATIVE_IMAGE", "true")()
+
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "test-classes", "META-INF", "native-image", "reflect-config.json"), "[")
+
+				_, _, err = springboot.NewSpringBoot(f.Build)
+				g.Expect(err).To(gomega.MatchError(gomega.HavePrefix(
+					fmt.Sprintf("%s is not well-formed JSON", filepath.Join("test-classes", "META-INF", "native-image", "reflect-config.json")))))
+			})
+
+			it("requires native-image on PATH", func() {
+				defer test.ReplaceEnv(t, "PATH", "")()
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_NATIVE_IMAGE", "true")()
+
+				_, _, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(err).To(gomega.MatchError(gomega.HavePrefix("native-image must be on $PATH")))
+			})
+		})
+
+		when("support status", func() {
+
+			it.Before(func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: 1.5.22.RELEASE`)
+			})
+
+			it("contributes support status to plan", func() {
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				p, err := e.Plan()
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(p.Metadata["support"]).To(gomega.Equal(map[string]interface{}{
+					"release-line":   "1.5",
+					"status":         springboot.EndOfLife,
+					"oss-end":        "2019-08-06",
+					"commercial-end": "2020-11-06",
+				}))
+			})
+
+			it("fails on end of life when configured", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_FAIL_ON_EOL", "true")()
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.MatchError("Spring Boot 1.5 reached the end of support on 2020-11-06"))
+			})
+
+			it("fails below minimum version", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_MINIMUM_VERSION", "2.1")()
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.MatchError("Spring Boot 1.5.22.RELEASE is below the minimum version 2.1"))
+			})
+
+			it("rejects invalid minimum version", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_MINIMUM_VERSION", "two")()
+
+				_, _, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(err).To(gomega.MatchError("invalid $BP_SPRING_BOOT_MINIMUM_VERSION: two"))
+			})
+		})
+
+		when("version consistency", func() {
+
+			it.Before(func() {
+				for _, j := range []string{
+					"spring-beans-5.1.0.RELEASE.jar",
+					"spring-boot-2.2.5.RELEASE.jar",
+					"spring-boot-autoconfigure-2.2.4.RELEASE.jar",
+					"spring-core-5.2.4.RELEASE.jar",
+				} {
+					test.TouchFile(t, f.Build.Application.Root, "test-lib", j)
+				}
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: 2.2.5.RELEASE`)
+			})
+
+			it("reports mismatched Spring dependencies", func() {
+				var b bytes.Buffer
+				f.Build.Logger = logger.Logger{Logger: bplogger.NewLogger(nil, &b)}
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				g.Expect(b.String()).To(gomega.ContainSubstring("Found Spring dependencies that do not match Spring Boot 2.2.5.RELEASE"))
+				g.Expect(b.String()).To(gomega.ContainSubstring("spring-beans 5.1.0.RELEASE, expected 5.2.x"))
+				g.Expect(b.String()).To(gomega.ContainSubstring("spring-boot-autoconfigure 2.2.4.RELEASE, expected 2.2.5.RELEASE"))
+				g.Expect(b.String()).NotTo(gomega.ContainSubstring("spring-core"))
+			})
+
+			it("fails on mismatched Spring dependencies when configured", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_FAIL_ON_VERSION_MISMATCH", "true")()
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.MatchError("found 2 Spring dependencies that do not match Spring Boot 2.2.5.RELEASE"))
+			})
+		})
+
+		when("Jakarta EE", func() {
+
+			it.Before(func() {
+				for _, j := range []string{"test-servlet-1.0.0.jar", "test-xa-1.0.0.jar"} {
+					test.CopyFile(t, filepath.Join("testdata", j), filepath.Join(f.Build.Application.Root, "test-lib", j))
+				}
+			})
+
+			it("scans Spring Boot 3 dependencies for Java EE namespaces", func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: 3.0.0`)
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				p, err := e.Plan()
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				d := p.Metadata["dependencies"].(springboot.JARDependencies)
+				g.Expect(d).To(gomega.HaveLen(2))
+				g.Expect(d[0].Name).To(gomega.Equal("test-servlet"))
+				g.Expect(d[0].EENamespaces).To(gomega.Equal([]string{"javax.annotation", "javax.servlet"}))
+				g.Expect(d[1].Name).To(gomega.Equal("test-xa"))
+				g.Expect(d[1].EENamespaces).To(gomega.BeEmpty())
+			})
+
+			it("skips entries that cannot be read", func() {
+				test.CopyFile(t, filepath.Join("testdata", "test-corrupt-1.0.0.jar"),
+					filepath.Join(f.Build.Application.Root, "test-lib", "test-corrupt-1.0.0.jar"))
+
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: 3.0.0`)
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				p, err := e.Plan()
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				d := p.Metadata["dependencies"].(springboot.JARDependencies)
+				g.Expect(d).To(gomega.HaveLen(3))
+				g.Expect(d[0].Name).To(gomega.Equal("test-corrupt"))
+				g.Expect(d[0].Module).To(gomega.Equal("test.corrupt"))
+				g.Expect(d[0].EENamespaces).To(gomega.Equal([]string{"javax.annotation", "javax.servlet"}))
+			})
+
+			it("does not scan Spring Boot 2 dependencies by default", func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: 2.2.5.RELEASE`)
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				p, err := e.Plan()
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				for _, d := range p.Metadata["dependencies"].(springboot.JARDependencies) {
+					g.Expect(d.EENamespaces).To(gomega.BeEmpty())
+				}
+			})
+
+			it("scans Spring Boot 2 dependencies when configured", func() {
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: 2.2.5.RELEASE`)
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_JAKARTA_REPORT", "true")()
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				p, err := e.Plan()
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				d := p.Metadata["dependencies"].(springboot.JARDependencies)
+				g.Expect(d[0].EENamespaces).To(gomega.Equal([]string{"javax.annotation", "javax.servlet"}))
+			})
+		})
+
+		when("Java agents", func() {
+
+			it.Before(func() {
+				for _, j := range []string{"aspectjweaver-1.9.5.jar", "test-agent-1.0.0.jar"} {
+					test.CopyFile(t, filepath.Join("testdata", j), filepath.Join(f.Build.Application.Root, "test-lib", j))
+				}
+				test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+					`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+			})
+
+			it("attaches allowed Java agents", func() {
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				layer := f.Build.Layers.Layer("spring-boot")
+				g.Expect(layer).To(test.HaveAppendLaunchEnvironment("JAVA_OPTS", " -javaagent:%s",
+					filepath.Join(f.Build.Application.Root, "test-lib", "aspectjweaver-1.9.5.jar")))
+
+				p, err := e.Plan()
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				for _, d := range p.Metadata["dependencies"].(springboot.JARDependencies) {
+					g.Expect(d.Agent).To(gomega.BeTrue())
+				}
+			})
+
+			it("attaches configured Java agents", func() {
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_ALLOWED_AGENTS", "test-agent")()
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				g.Expect(filepath.Join(f.Build.Layers.Layer("spring-boot").Root, "env.launch", "JAVA_OPTS.append")).
+					To(test.HaveContent(fmt.Sprintf(" -javaagent:%s -javaagent:%s",
+						filepath.Join(f.Build.Application.Root, "test-lib", "aspectjweaver-1.9.5.jar"),
+						filepath.Join(f.Build.Application.Root, "test-lib", "test-agent-1.0.0.jar"))))
+			})
+
+			it("does not attach launcher agents", func() {
+				test.CopyFile(t, filepath.Join("testdata", "test-launcher-agent-1.0.0.jar"),
+					filepath.Join(f.Build.Application.Root, "test-lib", "test-launcher-agent-1.0.0.jar"))
+				defer test.ReplaceEnv(t, "BP_SPRING_BOOT_ALLOWED_AGENTS", "test-launcher-agent")()
+
+				var b bytes.Buffer
+				f.Build.Logger = logger.Logger{Logger: bplogger.NewLogger(nil, &b)}
+
+				e, ok, err := springboot.NewSpringBoot(f.Build)
+				g.Expect(ok).To(gomega.BeTrue())
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				g.Expect(e.Contribute()).To(gomega.Succeed())
+
+				layer := f.Build.Layers.Layer("spring-boot")
+				g.Expect(layer).To(test.HaveAppendLaunchEnvironment("JAVA_OPTS", " -javaagent:%s",
+					filepath.Join(f.Build.Application.Root, "test-lib", "aspectjweaver-1.9.5.jar")))
+
+				g.Expect(b.String()).To(gomega.ContainSubstring("Ignoring Java agents packaged in the application"))
+				g.Expect(b.String()).To(gomega.ContainSubstring("test-launcher-agent 1.0.0 (Launcher-Agent-Class only"))
+			})
+		})
+
+		it("contributes debug process type", func() {
+			test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+				`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+			defer test.ReplaceEnv(t, "BP_DEBUG_ENABLED", "true")()
+			defer test.ReplaceEnv(t, "BP_DEBUG_PORT", "8001")()
+
+			e, ok, err := springboot.NewSpringBoot(f.Build)
+			g.Expect(ok).To(gomega.BeTrue())
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			g.Expect(e.Contribute()).To(gomega.Succeed())
+
+			layer := f.Build.Layers.Layer("spring-boot")
+			g.Expect(layer).To(test.HaveDefaultLaunchEnvironment("JAVA_DEBUG_OPTS",
+				"-agentlib:jdwp=transport=dt_socket,server=y,address=*:8001,suspend=n"))
+
+			command := "java -cp $CLASSPATH $JAVA_OPTS test-start-class"
+			g.Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+				Slices: layers.Slices{{}, {}, {}, {}, {Paths: []string{"META-INF/MANIFEST.MF"}}},
+				Processes: layers.Processes{
+					{Type: "debug", Command: "java -cp $CLASSPATH $JAVA_OPTS $JAVA_DEBUG_OPTS test-start-class"},
+					{Type: "spring-boot", Command: command},
+					{Type: "task", Command: command},
+					{Type: "web", Command: command},
+				},
+			}))
+		})
+
+		it("removes stale Spring configuration from launch", func() {
+			test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+				`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+			layer := f.Build.Layers.Layer("spring-boot")
+			test.WriteFile(t, filepath.Join(layer.Root, "env.launch", "SPRING_PROFILES_ACTIVE.default"), "production")
+
+			e, ok, err := springboot.NewSpringBoot(f.Build)
+			g.Expect(ok).To(gomega.BeTrue())
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			g.Expect(e.Contribute()).To(gomega.Succeed())
+
+			g.Expect(filepath.Join(layer.Root, "env.launch", "SPRING_PROFILES_ACTIVE.default")).NotTo(gomega.BeAnExistingFile())
+		})
+
+		it("contributes configured process types", func() {
+			test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+				`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+			defer test.ReplaceEnv(t, "BP_SPRING_BOOT_PROCESSES", "worker=--spring.main.web-application-type=none")()
+			defer test.ReplaceEnv(t, "BP_SPRING_BOOT_REMOVE_PROCESSES", "task")()
+
+			e, ok, err := springboot.NewSpringBoot(f.Build)
+			g.Expect(ok).To(gomega.BeTrue())
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			g.Expect(e.Contribute()).To(gomega.Succeed())
+
+			command := "java -cp $CLASSPATH $JAVA_OPTS test-start-class"
+			g.Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+				Slices: layers.Slices{
+					{},
+					{},
+					{},
+					{},
+					{Paths: []string{"META-INF/MANIFEST.MF"}},
+				},
+				Processes: layers.Processes{
+					{Type: "spring-boot", Command: command},
+					{Type: "web", Command: command},
+					{Type: "worker", Command: command + " --spring.main.web-application-type=none"},
+				},
+			}))
+		})
+
+		it("contributes additional classpath entries to slices", func() {
+			test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+				`
+Class-Path: ext/extension-1.0.0.jar
+Main-Class: org.springframework.boot.loader.PropertiesLauncher
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+			test.WriteFile(t, filepath.Join(f.Build.Application.Root, "test-classes", "loader.properties"), "loader.path=plugins")
+			test.TouchFile(t, f.Build.Application.Root, "plugins", "plugin-1.0.0-SNAPSHOT.jar")
+			test.TouchFile(t, f.Build.Application.Root, "ext", "extension-1.0.0.jar")
+
+			e, ok, err := springboot.NewSpringBoot(f.Build)
+			g.Expect(ok).To(gomega.BeTrue())
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			g.Expect(e.Contribute()).To(gomega.Succeed())
+
+			command := "java -cp $CLASSPATH $JAVA_OPTS test-start-class"
+			g.Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+				Slices: layers.Slices{
+					{},
+					{Paths: []string{"ext/extension-1.0.0.jar"}},
+					{Paths: []string{"plugins/plugin-1.0.0-SNAPSHOT.jar"}},
+					{Paths: []string{"test-classes/loader.properties"}},
+					{Paths: []string{"META-INF/MANIFEST.MF"}},
+				},
+				Processes: layers.Processes{
+					{Type: "spring-boot", Command: command},
+					{Type: "task", Command: command},
+					{Type: "web", Command: command},
+				},
+			}))
+
+			p, err := e.Plan()
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(p.Metadata["dependencies"]).To(gomega.HaveLen(2))
+		})
+
+		it("contributes slices for Spring Boot 1.x layout", func() {
+			test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+				`
+Start-Class: test-start-class
+Spring-Boot-Version: 1.3.8.RELEASE`)
+			test.TouchFile(t, f.Build.Application.Root, "org", "cloudfoundry", "Test.class")
+			test.TouchFile(t, f.Build.Application.Root, "org", "springframework", "boot", "loader", "JarLauncher.class")
+			test.TouchFile(t, f.Build.Application.Root, "lib", "test-1.2.3.jar")
+
+			e, ok, err := springboot.NewSpringBoot(f.Build)
+			g.Expect(ok).To(gomega.BeTrue())
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			g.Expect(e.Contribute()).To(gomega.Succeed())
+
+			command := "java -cp $CLASSPATH $JAVA_OPTS test-start-class"
+			g.Expect(f.Build.Layers).To(test.HaveApplicationMetadata(layers.Metadata{
+				Slices: layers.Slices{
+					{Paths: []string{"org/springframework/boot/loader/JarLauncher.class"}},
+					{Paths: []string{"lib/test-1.2.3.jar"}},
+					{},
+					{Paths: []string{"org/cloudfoundry/Test.class"}},
+					{Paths: []string{"META-INF/MANIFEST.MF"}},
+				},
+				Processes: layers.Processes{
+					{Type: "spring-boot", Command: command},
+					{Type: "task", Command: command},
+					{Type: "web", Command: command},
+				},
+			}))
+		})
+
+		it("rejects invalid process configuration", func() {
+			test.WriteFile(t, filepath.Join(f.Build.Application.Root, "META-INF", "MANIFEST.MF"),
+				`
+Spring-Boot-Classes: test-classes
+Spring-Boot-Lib: test-lib
+Start-Class: test-start-class
+Spring-Boot-Version: test-version`)
+			defer test.ReplaceEnv(t, "BP_SPRING_BOOT_DEFAULT_PROCESS", "worker")()
+
+			e, ok, err := springboot.NewSpringBoot(f.Build)
+			g.Expect(ok).To(gomega.BeTrue())
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			g.Expect(e.Contribute()).To(gomega.MatchError("default process type worker is not defined"))
+		})
 	}, spec.Report(report.Terminal{}))
 }
@@ -0,0 +1,223 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/logger"
+)
+
+const (
+	// Supported indicates that a Spring Boot release line has OSS support.
+	Supported = "supported"
+
+	// CommercialOnly indicates that a Spring Boot release line has reached the end of OSS support but still has
+	// commercial support.
+	CommercialOnly = "commercial-only"
+
+	// EndOfLife indicates that a Spring Boot release line has reached the end of both OSS and commercial support.
+	EndOfLife = "end-of-life"
+)
+
+// releaseLines are the end of OSS and commercial support dates of Spring Boot release lines.
+var releaseLines = map[string][2]string{
+	"1.5": {"2019-08-06", "2020-11-06"},
+	"2.0": {"2019-03-01", "2020-06-01"},
+	"2.1": {"2020-10-30", "2022-01-30"},
+	"2.2": {"2020-10-16", "2022-01-16"},
+	"2.3": {"2021-05-20", "2022-08-20"},
+	"2.4": {"2021-11-18", "2023-02-18"},
+	"2.5": {"2022-05-19", "2023-08-24"},
+	"2.6": {"2022-11-24", "2024-02-24"},
+	"2.7": {"2023-11-24", "2026-06-30"},
+	"3.0": {"2023-12-31", "2024-12-31"},
+	"3.1": {"2024-06-30", "2025-06-30"},
+	"3.2": {"2024-12-31", "2025-12-31"},
+	"3.3": {"2025-06-30", "2026-06-30"},
+	"3.4": {"2025-12-31", "2026-12-31"},
+	"3.5": {"2026-06-30", "2032-06-30"},
+	"4.0": {"2026-12-31", "2027-12-31"},
+}
+
+// SupportPolicy is the handling of Spring Boot release lines that are not supported.
+type SupportPolicy struct {
+	// FailOnEOL indicates whether the build fails if the release line has reached the end of life.
+	FailOnEOL bool
+
+	// MinimumVersion is the <major>.<minor> release line below which the build fails.  Empty if there is no minimum.
+	MinimumVersion string
+}
+
+// NewSupportPolicy creates a new SupportPolicy from the $BP_SPRING_BOOT_FAIL_ON_EOL and
+// $BP_SPRING_BOOT_MINIMUM_VERSION environment variables.
+func NewSupportPolicy() (SupportPolicy, error) {
+	p := SupportPolicy{}
+
+	var err error
+	if p.FailOnEOL, err = boolEnv("BP_SPRING_BOOT_FAIL_ON_EOL"); err != nil {
+		return SupportPolicy{}, err
+	}
+
+	if m, ok := os.LookupEnv("BP_SPRING_BOOT_MINIMUM_VERSION"); ok {
+		if _, err := parseReleaseLine(strings.TrimSpace(m)); err != nil {
+			return SupportPolicy{}, fmt.Errorf("invalid $BP_SPRING_BOOT_MINIMUM_VERSION: %s", m)
+		}
+
+		p.MinimumVersion = strings.TrimSpace(m)
+	}
+
+	return p, nil
+}
+
+// check fails if the release line of a Spring Boot version has reached the end of life or is below the minimum
+// version, as configured.
+func (p SupportPolicy) check(support Support, version string, logger logger.Logger) error {
+	if p.FailOnEOL && support.Status == EndOfLife {
+		return fmt.Errorf("%s", support.endOfLife())
+	}
+
+	if p.MinimumVersion == "" {
+		return nil
+	}
+
+	if l := releaseLine(version); l == "" {
+		logger.HeaderWarning("Unable to compare Spring Boot %s to minimum version %s", version, p.MinimumVersion)
+	} else if c, err := compareReleaseLines(l, p.MinimumVersion); err != nil {
+		return err
+	} else if c < 0 {
+		return fmt.Errorf("Spring Boot %s is below the minimum version %s", version, p.MinimumVersion)
+	}
+
+	return nil
+}
+
+// Support is the support status of a Spring Boot release line.
+type Support struct {
+	// ReleaseLine is the <major>.<minor> release line.
+	ReleaseLine string `mapstructure:"release-line"`
+
+	// Status is one of Supported, CommercialOnly, or EndOfLife.
+	Status string `mapstructure:"status"`
+
+	// OSSEnd is the date that OSS support ends.
+	OSSEnd string `mapstructure:"oss-end"`
+
+	// CommercialEnd is the date that commercial support ends.
+	CommercialEnd string `mapstructure:"commercial-end"`
+}
+
+// Log reports release lines that are not supported.
+func (s Support) Log(logger logger.Logger) {
+	switch s.Status {
+	case CommercialOnly:
+		logger.HeaderWarning("Spring Boot %s reached the end of OSS support on %s, commercial support ends on %s",
+			s.ReleaseLine, s.OSSEnd, s.CommercialEnd)
+	case EndOfLife:
+		logger.HeaderWarning("%s", s.endOfLife())
+	}
+}
+
+func (s Support) endOfLife() string {
+	if s.CommercialEnd == "" {
+		return fmt.Sprintf("Spring Boot %s has reached the end of support", s.ReleaseLine)
+	}
+
+	return fmt.Sprintf("Spring Boot %s reached the end of support on %s", s.ReleaseLine, s.CommercialEnd)
+}
+
+// NewSupport returns the support status of a Spring Boot version at a point in time, returning false if the release
+// line is unknown.  Release lines older than the oldest known release line have reached the end of life.
+func NewSupport(version string, now time.Time) (Support, bool) {
+	l := releaseLine(version)
+
+	d, ok := releaseLines[l]
+	if !ok {
+		if c, err := compareReleaseLines(l, oldestReleaseLine()); err != nil || c >= 0 {
+			return Support{}, false
+		}
+
+		return Support{ReleaseLine: l, Status: EndOfLife}, true
+	}
+
+	s := Support{ReleaseLine: l, Status: Supported, OSSEnd: d[0], CommercialEnd: d[1]}
+
+	today := now.Format("2006-01-02")
+	if today > s.CommercialEnd {
+		s.Status = EndOfLife
+	} else if today > s.OSSEnd {
+		s.Status = CommercialOnly
+	}
+
+	return s, true
+}
+
+func oldestReleaseLine() string {
+	var o string
+
+	for l := range releaseLines {
+		if o == "" {
+			o = l
+		} else if c, err := compareReleaseLines(l, o); err == nil && c < 0 {
+			o = l
+		}
+	}
+
+	return o
+}
+
+// compareReleaseLines compares two <major>.<minor> release lines, returning a negative number if a is less than b,
+// zero if they are equal, and a positive number if a is greater than b.
+func compareReleaseLines(a string, b string) (int, error) {
+	pa, err := parseReleaseLine(a)
+	if err != nil {
+		return 0, err
+	}
+
+	pb, err := parseReleaseLine(b)
+	if err != nil {
+		return 0, err
+	}
+
+	if pa[0] != pb[0] {
+		return pa[0] - pb[0], nil
+	}
+
+	return pa[1] - pb[1], nil
+}
+
+func parseReleaseLine(line string) ([2]int, error) {
+	p := strings.SplitN(line, ".", 2)
+	if len(p) != 2 {
+		return [2]int{}, fmt.Errorf("invalid release line %q, must be <major>.<minor>", line)
+	}
+
+	var r [2]int
+	for i, s := range p {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return [2]int{}, fmt.Errorf("invalid release line %q, must be <major>.<minor>", line)
+		}
+		r[i] = n
+	}
+
+	return r, nil
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudfoundry/spring-boot-cnb/springboot"
+	"github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestSupport(t *testing.T) {
+	spec.Run(t, "Support", func(t *testing.T, when spec.G, it spec.S) {
+
+		g := gomega.NewWithT(t)
+
+		it("returns false for unknown release lines", func() {
+			_, ok := springboot.NewSupport("test-version", time.Now())
+			g.Expect(ok).To(gomega.BeFalse())
+		})
+
+		it("returns supported", func() {
+			s, ok := springboot.NewSupport("2.2.5.RELEASE", time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC))
+			g.Expect(ok).To(gomega.BeTrue())
+			g.Expect(s).To(gomega.Equal(springboot.Support{
+				ReleaseLine:   "2.2",
+				Status:        springboot.Supported,
+				OSSEnd:        "2020-10-16",
+				CommercialEnd: "2022-01-16",
+			}))
+		})
+
+		it("returns commercial only", func() {
+			s, _ := springboot.NewSupport("2.2.5.RELEASE", time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC))
+			g.Expect(s.Status).To(gomega.Equal(springboot.CommercialOnly))
+		})
+
+		it("returns end of life", func() {
+			s, _ := springboot.NewSupport("2.2.5.RELEASE", time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC))
+			g.Expect(s.Status).To(gomega.Equal(springboot.EndOfLife))
+		})
+
+		it("returns end of life for release lines older than the oldest known release line", func() {
+			s, ok := springboot.NewSupport("1.4.7.RELEASE", time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC))
+			g.Expect(ok).To(gomega.BeTrue())
+			g.Expect(s).To(gomega.Equal(springboot.Support{
+				ReleaseLine: "1.4",
+				Status:      springboot.EndOfLife,
+			}))
+		})
+
+		it("returns false for release lines newer than the newest known release line", func() {
+			_, ok := springboot.NewSupport("9.0.0", time.Now())
+			g.Expect(ok).To(gomega.BeFalse())
+		})
+
+		it("returns supported for 4.0", func() {
+			s, ok := springboot.NewSupport("4.0.0", time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC))
+			g.Expect(ok).To(gomega.BeTrue())
+			g.Expect(s.Status).To(gomega.Equal(springboot.Supported))
+		})
+	}, spec.Report(report.Terminal{}))
+}
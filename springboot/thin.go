@@ -0,0 +1,243 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/helper"
+	"github.com/cloudfoundry/libcfbuildpack/v2/layers"
+	"github.com/magiconair/properties"
+)
+
+const (
+	// ThinDependency is the name of the layer containing the dependencies of a thin launcher application.
+	ThinDependency = "thin-dependencies"
+
+	// ThinRepositoryBinding is the type of a binding whose contents are a Maven repository.
+	ThinRepositoryBinding = "maven-repository"
+)
+
+// thinArtifact is a dependency of a thin launcher application resolved from a Maven repository.
+type thinArtifact struct {
+	// Coordinates are the Maven coordinates of the artifact.
+	Coordinates string `toml:"coordinates"`
+
+	// File is the path of the artifact in the layer, <groupId>/<artifactId>-<version>[-<classifier>].<extension>, so that
+	// artifacts with the same artifactId in different groups do not collide.
+	File string `toml:"file"`
+
+	// SHA256 is the SHA256 hash of the artifact.
+	SHA256 string `toml:"sha256"`
+
+	source string
+}
+
+// thin represents the dependencies of a thin launcher application.
+type thin struct {
+	Artifacts []thinArtifact `toml:"artifacts"`
+}
+
+func (t thin) Identity() (string, string) {
+	return "Thin Launcher Dependencies", ""
+}
+
+// Contribute copies the artifacts into a layer marked cache and launch.
+func (t thin) Contribute(layer layers.Layer) error {
+	return layer.Contribute(t, func(layer layers.Layer) error {
+		if err := os.RemoveAll(layer.Root); err != nil {
+			return err
+		}
+
+		for _, a := range t.Artifacts {
+			layer.Logger.Body("Copying %s", a.Coordinates)
+			if err := helper.CopyFile(a.source, filepath.Join(layer.Root, filepath.FromSlash(a.File))); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, layers.Cache, layers.Launch)
+}
+
+// newThin resolves the dependencies in the computed META-INF/thin.properties of a thin launcher application from a
+// Maven repository, verifying each artifact's checksum.  OK is false if the application has no thin.properties.  Only
+// called for applications without a Spring-Boot-Lib, whose dependencies are already packaged.
+func newThin(root string, repository string) (thin, bool, error) {
+	f := filepath.Join(root, "META-INF", "thin.properties")
+	if exists, err := helper.FileExists(f); err != nil {
+		return thin{}, false, err
+	} else if !exists {
+		return thin{}, false, nil
+	}
+
+	p, err := properties.LoadFile(f, properties.UTF8)
+	if err != nil {
+		return thin{}, false, fmt.Errorf("unable to read %s: %w", f, err)
+	}
+
+	if !p.GetBool("computed", false) {
+		return thin{}, false, fmt.Errorf(
+			"META-INF/thin.properties must contain the computed dependencies, set computed=true when generating it")
+	}
+
+	if repository == "" {
+		return thin{}, false, fmt.Errorf("a Maven repository is required to resolve thin launcher dependencies, "+
+			"set $BP_SPRING_BOOT_THIN_REPOSITORY or bind a %s", ThinRepositoryBinding)
+	}
+
+	var keys []string
+	for _, k := range p.Keys() {
+		if strings.HasPrefix(k, "dependencies.") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	t := thin{}
+	for _, k := range keys {
+		a, err := resolveArtifact(repository, p.MustGetString(k))
+		if err != nil {
+			return thin{}, false, err
+		}
+
+		t.Artifacts = append(t.Artifacts, a)
+	}
+
+	return t, true, nil
+}
+
+// resolveArtifact resolves coordinates in the form <groupId>:<artifactId>[:<extension>[:<classifier>]]:<version>.
+func resolveArtifact(repository string, coordinates string) (thinArtifact, error) {
+	c := strings.Split(coordinates, ":")
+	if len(c) < 3 || len(c) > 5 {
+		return thinArtifact{}, fmt.Errorf("invalid Maven coordinates %q", coordinates)
+	}
+
+	group, artifact, version := c[0], c[1], c[len(c)-1]
+	extension, classifier := "jar", ""
+	if len(c) > 3 {
+		extension = c[2]
+	}
+	if len(c) > 4 {
+		classifier = "-" + c[3]
+	}
+
+	file := fmt.Sprintf("%s-%s%s.%s", artifact, version, classifier, extension)
+	source := filepath.Join(repository, filepath.FromSlash(strings.ReplaceAll(group, ".", "/")), artifact, version, file)
+
+	if exists, err := helper.FileExists(source); err != nil {
+		return thinArtifact{}, err
+	} else if !exists {
+		return thinArtifact{}, fmt.Errorf("unable to resolve %s, %s does not exist", coordinates, source)
+	}
+
+	if err := verifyChecksum(source); err != nil {
+		return thinArtifact{}, fmt.Errorf("unable to verify %s: %w", coordinates, err)
+	}
+
+	h, err := hash(source)
+	if err != nil {
+		return thinArtifact{}, err
+	}
+
+	return thinArtifact{Coordinates: coordinates, File: path.Join(group, file), SHA256: h, source: source}, nil
+}
+
+// verifyChecksum verifies a file against the strongest of the .sha512, .sha256, and .sha1 checksum files beside it.
+func verifyChecksum(file string) error {
+	for _, c := range []struct {
+		extension string
+		sum       func([]byte) []byte
+	}{
+		{".sha512", func(b []byte) []byte { s := sha512.Sum512(b); return s[:] }},
+		{".sha256", func(b []byte) []byte { s := sha256.Sum256(b); return s[:] }},
+		{".sha1", func(b []byte) []byte { s := sha1.Sum(b); return s[:] }},
+	} {
+		expected, err := ioutil.ReadFile(file + c.extension)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		fields := strings.Fields(string(expected))
+		if len(fields) == 0 {
+			return fmt.Errorf("%s is empty", file+c.extension)
+		}
+
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		if actual := hex.EncodeToString(c.sum(b)); !strings.EqualFold(actual, fields[0]) {
+			return fmt.Errorf("checksum %s does not match expected %s", actual, fields[0])
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("no .sha512, .sha256, or .sha1 checksum found for %s", file)
+}
+
+// thinRepository returns the Maven repository configured with $BP_SPRING_BOOT_THIN_REPOSITORY or the first binding,
+// in $SERVICE_BINDING_ROOT or the platform's bindings, whose type is maven-repository.
+func thinRepository(platform string) (string, error) {
+	if s, ok := os.LookupEnv("BP_SPRING_BOOT_THIN_REPOSITORY"); ok && s != "" {
+		return s, nil
+	}
+
+	roots := []string{filepath.Join(platform, "bindings")}
+	if s, ok := os.LookupEnv("SERVICE_BINDING_ROOT"); ok && s != "" {
+		roots = append([]string{s}, roots...)
+	}
+
+	for _, r := range roots {
+		c, err := ioutil.ReadDir(r)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+
+		for _, b := range c {
+			t, err := ioutil.ReadFile(filepath.Join(r, b.Name(), "type"))
+			if os.IsNotExist(err) {
+				continue
+			} else if err != nil {
+				return "", err
+			}
+
+			if strings.TrimSpace(string(t)) == ThinRepositoryBinding {
+				return filepath.Join(r, b.Name()), nil
+			}
+		}
+	}
+
+	return "", nil
+}
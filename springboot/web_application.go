@@ -0,0 +1,288 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package springboot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudfoundry/libcfbuildpack/v2/helper"
+	"github.com/magiconair/properties"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// NoneWebApplication indicates an application that does not run an embedded web server.
+	NoneWebApplication = "none"
+
+	// ReactiveWebApplication indicates an application that runs an embedded reactive web server with Spring WebFlux.
+	ReactiveWebApplication = "reactive"
+
+	// ServletWebApplication indicates an application that runs an embedded servlet web server.
+	ServletWebApplication = "servlet"
+)
+
+// webApplicationTypeProperty is the Spring property that overrides the deduced type of web application.
+const webApplicationTypeProperty = "spring.main.web-application-type"
+
+var (
+	// servletAPI are the dependencies that contain the Servlet API.
+	servletAPI = []string{
+		"jakarta.servlet-api", "javax.servlet-api", "jetty-jakarta-servlet-api", "jetty-servlet-api", "tomcat-embed-core",
+	}
+
+	// webServers are the dependencies of each embedded web server, in the order Spring Boot prefers them.  Reactor Netty
+	// only serves reactive web applications.
+	webServers = []struct {
+		dependencies []string
+		name         string
+		reactive     bool
+	}{
+		{[]string{"tomcat-embed-core"}, "tomcat", false},
+		{[]string{"jetty-server"}, "jetty", false},
+		{[]string{"undertow-core"}, "undertow", false},
+		{[]string{"reactor-netty", "reactor-netty-http"}, "reactor-netty", true},
+	}
+
+	// webServerNames are the names of the embedded web servers displayed in the build log.
+	webServerNames = map[string]string{
+		"jetty":         "Jetty",
+		"none":          "no embedded web server",
+		"reactor-netty": "Reactor Netty",
+		"tomcat":        "Tomcat",
+		"undertow":      "Undertow",
+	}
+)
+
+// webApplication deduces the type of web application and its embedded web server from the dependencies on the
+// classpath, following the rules of Spring Boot's WebApplicationType, unless the type is configured.  The web server
+// is none if no embedded web server is found.
+func webApplication(dependencies JARDependencies, configured string) (string, string) {
+	has := func(names ...string) bool {
+		for _, d := range dependencies {
+			for _, n := range names {
+				if d.Name == n {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+
+	t := configured
+	if t == "" {
+		if has("spring-webflux") && !has("spring-webmvc") && !has("jersey-container-servlet-core") {
+			t = ReactiveWebApplication
+		} else if has(servletAPI...) && has("spring-web") {
+			t = ServletWebApplication
+		} else {
+			t = NoneWebApplication
+		}
+	}
+
+	if t == NoneWebApplication {
+		return t, "none"
+	}
+
+	for _, w := range webServers {
+		if (!w.reactive || t == ReactiveWebApplication) && has(w.dependencies...) {
+			return t, w.name
+		}
+	}
+
+	return t, "none"
+}
+
+// providedDependencies returns the dependencies in the lib-provided directory beside Spring-Boot-Lib.  Executable wars
+// package their embedded web server there so that the war can also be deployed to a servlet container.  The
+// dependencies are identified by their file names only.
+func providedDependencies(root string, lib string) (JARDependencies, error) {
+	if lib == "" {
+		return nil, nil
+	}
+
+	d := filepath.Join(root, filepath.FromSlash(strings.TrimSuffix(lib, "/"))+"-provided")
+
+	c, err := ioutil.ReadDir(d)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var deps JARDependencies
+	for _, f := range c {
+		if m := pattern.FindStringSubmatch(filepath.Join(d, f.Name())); m != nil && !f.IsDir() {
+			deps = append(deps, JARDependency{Name: m[1], Version: m[2]})
+		}
+	}
+
+	return deps, nil
+}
+
+// configuredWebApplicationType returns the spring.main.web-application-type configured in $SPRING_APPLICATION_JSON or
+// the application.properties and application.yml files in Spring-Boot-Classes, in Spring Boot's order of precedence.
+// Empty if the type is not configured.
+func configuredWebApplicationType(root string, classes string, applicationJSON string) (string, error) {
+	if applicationJSON != "" {
+		var document map[string]interface{}
+		if err := json.Unmarshal([]byte(applicationJSON), &document); err != nil {
+			return "", err
+		}
+
+		if v, ok := flatten(document)[webApplicationTypeProperty]; ok {
+			return validWebApplicationType(v, "$SPRING_APPLICATION_JSON")
+		}
+	}
+
+	if ok, err := isClassesDirectory(root, classes); err != nil || !ok {
+		return "", err
+	}
+
+	for _, d := range []string{"config", ""} {
+		for _, n := range []string{"application.properties", "application.yml", "application.yaml"} {
+			f := filepath.Join(root, classes, d, n)
+
+			if exists, err := helper.FileExists(f); err != nil {
+				return "", err
+			} else if !exists {
+				continue
+			}
+
+			var p map[string]string
+			var err error
+			if filepath.Ext(n) == ".properties" {
+				p, err = readPropertiesFile(f)
+			} else {
+				p, err = readYAMLFile(f)
+			}
+			if err != nil {
+				return "", fmt.Errorf("unable to read %s: %w", filepath.Join(classes, d, n), err)
+			}
+
+			if v, ok := p[webApplicationTypeProperty]; ok {
+				return validWebApplicationType(v, filepath.Join(classes, d, n))
+			}
+		}
+	}
+
+	return "", nil
+}
+
+func validWebApplicationType(value string, source string) (string, error) {
+	switch t := strings.ToLower(strings.TrimSpace(value)); t {
+	case NoneWebApplication, ReactiveWebApplication, ServletWebApplication:
+		return t, nil
+	default:
+		return "", fmt.Errorf("invalid %s in %s: %s", webApplicationTypeProperty, source, value)
+	}
+}
+
+func readPropertiesFile(path string) (map[string]string, error) {
+	// Placeholders are resolved by Spring at launch.
+	l := properties.Loader{Encoding: properties.UTF8, DisableExpansion: true}
+
+	p, err := l.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Map(), nil
+}
+
+// readYAMLFile returns the properties of the documents of a YAML file that are not activated by a profile.  Later
+// documents override earlier ones.
+func readYAMLFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	p := make(map[string]string)
+
+	d := yaml.NewDecoder(f)
+	for {
+		var document map[string]interface{}
+		if err := d.Decode(&document); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		m := flatten(document)
+		if _, ok := m["spring.profiles"]; ok {
+			continue
+		}
+		if _, ok := m["spring.config.activate.on-profile"]; ok {
+			continue
+		}
+
+		for k, v := range m {
+			p[k] = v
+		}
+	}
+
+	return p, nil
+}
+
+// flatten returns the scalar values of a nested JSON or YAML document keyed by their dotted property names.
+func flatten(document map[string]interface{}) map[string]string {
+	m := make(map[string]string)
+
+	var f func(prefix string, value interface{})
+	f = func(prefix string, value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for k, c := range v {
+				f(joinProperty(prefix, k), c)
+			}
+		case map[interface{}]interface{}:
+			for k, c := range v {
+				f(joinProperty(prefix, fmt.Sprint(k)), c)
+			}
+		case []interface{}:
+			for i, c := range v {
+				f(fmt.Sprintf("%s[%d]", prefix, i), c)
+			}
+		case nil:
+			m[prefix] = ""
+		default:
+			m[prefix] = fmt.Sprint(v)
+		}
+	}
+
+	for k, v := range document {
+		f(k, v)
+	}
+
+	return m
+}
+
+func joinProperty(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}